@@ -0,0 +1,349 @@
+// Package accesslog는 Apache Common/Combined, JSON lines, logfmt 형식의 접근 로그를 기록하는 미들웨어를 제공합니다.
+package accesslog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"full_stack_service_networking_project/internal/identity"
+)
+
+// Format: 접근 로그 형식
+type Format string
+
+const (
+	Common   Format = "common"   // Apache Common Log Format
+	Combined Format = "combined" // Apache Combined Log Format (Referer, User-Agent 추가)
+	JSON     Format = "json"     // JSON lines
+	Logfmt   Format = "logfmt"   // key=value
+)
+
+// ParseFormat: 문자열을 Format으로 변환합니다.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case Common, Combined, JSON, Logfmt:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown access log format %q (expected common, combined, json or logfmt)", s)
+}
+
+// Level: 로그 수준
+type Level int
+
+const (
+	LevelInfo  Level = iota // 요청마다 접근 로그 한 줄
+	LevelDebug              // 접근 로그 + 요청 상세 정보 덤프
+)
+
+// ParseLevel: 문자열을 Level로 변환합니다.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "info":
+		return LevelInfo, nil
+	case "debug":
+		return LevelDebug, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q (expected info or debug)", s)
+}
+
+// RequestIDHeader: 요청 ID를 주고받는 헤더
+const RequestIDHeader = "X-Request-ID"
+
+// Entry: 요청 하나에 대한 접근 로그 항목
+type Entry struct {
+	Time       time.Time
+	RemoteAddr string // IP (포트 제외)
+	RemotePort string
+	User       string // mTLS 클라이언트 인증서의 호출자 이름 (없으면 빈 문자열)
+	Method     string
+	URI        string
+	Proto      string
+	Status     int
+	Bytes      int64
+	Latency    time.Duration
+	Referer    string
+	UserAgent  string
+	RequestID  string
+}
+
+// Logger: 접근 로그 기록기
+type Logger struct {
+	mu     sync.Mutex
+	out    io.Writer
+	format Format
+	level  Level
+}
+
+// New: 출력 대상, 형식, 수준으로 Logger를 생성합니다.
+func New(out io.Writer, format Format, level Level) *Logger {
+	return &Logger{out: out, format: format, level: level}
+}
+
+// SetLevel: 로그 수준을 변경합니다.
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+// Level: 현재 로그 수준
+func (l *Logger) Level() Level {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level
+}
+
+type ctxKey struct{}
+
+// RequestID: 요청 컨텍스트에 저장된 요청 ID를 반환합니다.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// newRequestID: 128비트 임의 요청 ID를 생성합니다.
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID: 클라이언트가 보낸 요청 ID를 로그에 그대로 써도 안전한지 검사합니다.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// splitHostPort: RemoteAddr을 IP와 포트로 나눕니다.
+func splitHostPort(addr string) (string, string) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		// IPv6 또는 포맷이 다를 경우 전체 RemoteAddr을 IP로 사용
+		return addr, ""
+	}
+	return host, port
+}
+
+// Middleware: 요청 ID를 부여하고, 응답 완료 후 접근 로그를 기록하는 미들웨어
+func (l *Logger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		r = r.WithContext(context.WithValue(r.Context(), ctxKey{}, id))
+
+		host, port := splitHostPort(r.RemoteAddr)
+		if l.Level() >= LevelDebug {
+			l.dumpRequest(r, host, port, id)
+		}
+
+		rw := NewResponseWriter(w)
+		next.ServeHTTP(rw, r)
+
+		l.Log(&Entry{
+			Time:       start,
+			RemoteAddr: host,
+			RemotePort: port,
+			User:       userName(r),
+			Method:     r.Method,
+			URI:        r.URL.RequestURI(),
+			Proto:      r.Proto,
+			Status:     rw.Status(),
+			Bytes:      rw.BytesWritten(),
+			Latency:    time.Since(start),
+			Referer:    r.Referer(),
+			UserAgent:  r.UserAgent(),
+			RequestID:  id,
+		})
+	})
+}
+
+// userName: mTLS로 인증된 호출자 이름
+func userName(r *http.Request) string {
+	if id := identity.FromContext(r.Context()); id != nil {
+		return id.Name
+	}
+	return ""
+}
+
+// dumpRequest: 요청 상세 정보 출력 (파이썬의 print_http_request_detail, debug 수준)
+func (l *Logger) dumpRequest(r *http.Request, host, port, id string) {
+	var b strings.Builder
+	fmt.Fprintln(&b, "::Request ID       : ", id)
+	fmt.Fprintln(&b, "::Client address   : ", host)
+	fmt.Fprintln(&b, "::Client port      : ", port)
+	if user := userName(r); user != "" {
+		fmt.Fprintln(&b, "::Client identity  : ", user)
+	}
+	fmt.Fprintln(&b, "::Request command  : ", r.Method)
+	fmt.Fprintln(&b, "::Request line     : ", r.Proto+" "+r.URL.String())
+	fmt.Fprintln(&b, "::Request path     : ", r.URL.Path)
+	fmt.Fprintln(&b, "::Request version  : ", r.Proto)
+	l.write(b.String())
+}
+
+// Log: 항목 하나를 설정된 형식으로 기록합니다.
+func (l *Logger) Log(e *Entry) {
+	var line string
+	switch l.format {
+	case Combined:
+		line = formatCombined(e)
+	case JSON:
+		line = formatJSON(e)
+	case Logfmt:
+		line = formatLogfmt(e)
+	default:
+		line = formatCommon(e)
+	}
+	l.write(line + "\n")
+}
+
+func (l *Logger) write(s string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.out, s)
+}
+
+// formatCommon: %h %l %u %t "%r" %>s %b
+func formatCommon(e *Entry) string {
+	bytes := "-"
+	if e.Bytes > 0 {
+		bytes = strconv.FormatInt(e.Bytes, 10)
+	}
+	return fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %s`,
+		e.RemoteAddr, clfUser(e.User), e.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		e.Method, escapeQuoted(e.URI), e.Proto, e.Status, bytes)
+}
+
+// formatCombined: Common + "%{Referer}i" "%{User-agent}i"
+func formatCombined(e *Entry) string {
+	return fmt.Sprintf(`%s "%s" "%s"`, formatCommon(e), escapeQuoted(orDash(e.Referer)), escapeQuoted(orDash(e.UserAgent)))
+}
+
+func formatJSON(e *Entry) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false) // URI의 '&'를 \u0026으로 바꾸지 않음
+	enc.Encode(struct {
+		Time       string  `json:"time"`
+		RemoteAddr string  `json:"remote_addr"`
+		User       string  `json:"user,omitempty"`
+		Method     string  `json:"method"`
+		URI        string  `json:"uri"`
+		Proto      string  `json:"proto"`
+		Status     int     `json:"status"`
+		Bytes      int64   `json:"bytes"`
+		LatencyMS  float64 `json:"latency_ms"`
+		Referer    string  `json:"referer,omitempty"`
+		UserAgent  string  `json:"user_agent,omitempty"`
+		RequestID  string  `json:"request_id"`
+	}{
+		Time:       e.Time.Format(time.RFC3339Nano),
+		RemoteAddr: e.RemoteAddr,
+		User:       e.User,
+		Method:     e.Method,
+		URI:        e.URI,
+		Proto:      e.Proto,
+		Status:     e.Status,
+		Bytes:      e.Bytes,
+		LatencyMS:  float64(e.Latency.Microseconds()) / 1000,
+		Referer:    e.Referer,
+		UserAgent:  e.UserAgent,
+		RequestID:  e.RequestID,
+	})
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func formatLogfmt(e *Entry) string {
+	pairs := [][2]string{
+		{"time", e.Time.Format(time.RFC3339Nano)},
+		{"remote_addr", e.RemoteAddr},
+		{"user", e.User},
+		{"method", e.Method},
+		{"uri", e.URI},
+		{"proto", e.Proto},
+		{"status", strconv.Itoa(e.Status)},
+		{"bytes", strconv.FormatInt(e.Bytes, 10)},
+		{"latency", e.Latency.String()},
+		{"referer", e.Referer},
+		{"user_agent", e.UserAgent},
+		{"request_id", e.RequestID},
+	}
+	var b strings.Builder
+	for i, kv := range pairs {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(kv[0])
+		b.WriteByte('=')
+		b.WriteString(logfmtValue(kv[1]))
+	}
+	return b.String()
+}
+
+// logfmtValue: 공백, 따옴표, '=' 등이 포함된 값은 따옴표로 감쌉니다.
+func logfmtValue(v string) string {
+	if v == "" {
+		return `""`
+	}
+	if strings.ContainsAny(v, " \"=\\\t\r\n") || !isPrintable(v) {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+func isPrintable(s string) bool {
+	for _, c := range s {
+		if c < 0x20 || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeQuoted: 따옴표로 감싼 필드 안의 따옴표, 역슬래시, 제어 문자를 이스케이프합니다. (로그 위조 방지)
+func escapeQuoted(s string) string {
+	q := strconv.Quote(s)
+	return q[1 : len(q)-1]
+}
+
+// clfUser: Common Log Format의 %u 필드 (공백과 제어 문자는 '_'로 치환)
+func clfUser(user string) string {
+	if user == "" {
+		return "-"
+	}
+	return strings.Map(func(c rune) rune {
+		if c <= ' ' || c == '"' || c == 0x7f {
+			return '_'
+		}
+		return c
+	}, user)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
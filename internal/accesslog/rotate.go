@@ -0,0 +1,201 @@
+package accesslog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat: 교체된 파일 이름에 붙는 시각 형식
+const backupTimeFormat = "20060102-150405"
+
+// RotatingFile: 크기 또는 시간 기준으로 교체되는 로그 파일
+// 교체된 파일은 "<Path>.20060102-150405" 형식의 이름으로 보관됩니다.
+type RotatingFile struct {
+	Path       string
+	MaxSize    int64         // 이 크기(바이트)를 넘으면 교체 (0이면 사용 안 함)
+	Interval   time.Duration // 이 시간이 지나면 교체 (0이면 사용 안 함)
+	MaxBackups int           // 보관할 이전 파일 개수 (0이면 모두 보관)
+
+	mu       sync.Mutex
+	file     *os.File // 교체 중 다시 열지 못했으면 nil (다음 Write에서 다시 시도)
+	closed   bool     // Close가 호출됨
+	size     int64
+	openedAt time.Time
+}
+
+// OpenRotatingFile: 로그 파일을 추가 모드로 엽니다.
+func OpenRotatingFile(path string, maxSize int64, interval time.Duration, maxBackups int) (*RotatingFile, error) {
+	rf := &RotatingFile{Path: path, MaxSize: maxSize, Interval: interval, MaxBackups: maxBackups}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *RotatingFile) open() error {
+	f, err := os.OpenFile(rf.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	rf.file, rf.size, rf.openedAt = f, info.Size(), time.Now()
+	return nil
+}
+
+// Write: 필요하면 파일을 교체한 뒤 기록합니다.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.closed {
+		return 0, os.ErrClosed
+	}
+	if rf.file == nil {
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+	if rf.needsRotate(int64(len(p))) {
+		// 교체에 실패해도 원래 파일을 다시 열었으면 기록은 이어 가고, 교체는 다음 Write에서 다시 시도
+		if err := rf.rotate(); err != nil && rf.file == nil {
+			return 0, err
+		}
+	}
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+func (rf *RotatingFile) needsRotate(next int64) bool {
+	if rf.size == 0 {
+		return false
+	}
+	if rf.MaxSize > 0 && rf.size+next > rf.MaxSize {
+		return true
+	}
+	return rf.Interval > 0 && time.Since(rf.openedAt) >= rf.Interval
+}
+
+// Rotate: 현재 파일을 즉시 교체합니다.
+func (rf *RotatingFile) Rotate() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.closed {
+		return os.ErrClosed
+	}
+	if rf.file == nil {
+		return rf.open()
+	}
+	return rf.rotate()
+}
+
+// rotate: 현재 파일을 보관용 이름으로 바꾸고 새 파일을 엽니다.
+// 닫기나 이름 바꾸기에 실패하면 원래 경로를 다시 열어 기록을 이어 가며, 그마저 실패하면 rf.file은 nil로 남습니다.
+func (rf *RotatingFile) rotate() error {
+	closeErr := rf.file.Close()
+	rf.file = nil // 닫기에 실패해도 이 디스크립터로는 더 쓸 수 없음
+	if closeErr != nil {
+		return errors.Join(fmt.Errorf("close log file: %w", closeErr), rf.open())
+	}
+	stamp := time.Now().Format(backupTimeFormat)
+	backup := rf.Path + "." + stamp
+	// 같은 초에 여러 번 교체되면 번호를 붙여 덮어쓰기를 방지
+	for i := 1; ; i++ {
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			break
+		}
+		backup = fmt.Sprintf("%s.%s.%d", rf.Path, stamp, i)
+	}
+	if err := os.Rename(rf.Path, backup); err != nil {
+		return errors.Join(fmt.Errorf("rename log file: %w", err), rf.open())
+	}
+	rf.removeOldBackups()
+	return rf.open()
+}
+
+// removeOldBackups: MaxBackups를 넘는 오래된 파일을 삭제합니다.
+// rotate가 만든 "<Path>.<시각>[.<번호>]" 이름만 대상으로 하므로 같은 디렉토리의 다른 파일(access.log.gz 등)은 건드리지 않습니다.
+func (rf *RotatingFile) removeOldBackups() {
+	if rf.MaxBackups <= 0 {
+		return
+	}
+	backups := listBackups(rf.Path)
+	if len(backups) <= rf.MaxBackups {
+		return
+	}
+	for _, old := range backups[:len(backups)-rf.MaxBackups] {
+		os.Remove(old.path)
+	}
+}
+
+// backup: 교체된 로그 파일 하나
+type backup struct {
+	path  string
+	stamp string // backupTimeFormat 시각 (사전순 = 시간순)
+	seq   int    // 같은 초에 교체된 파일의 번호 (없으면 0)
+}
+
+// listBackups: path의 교체된 파일을 오래된 순서로 반환합니다.
+func listBackups(path string) []backup {
+	pattern := regexp.MustCompile(`^` + regexp.QuoteMeta(filepath.Base(path)) + `\.(\d{8}-\d{6})(?:\.(\d+))?$`)
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+	var backups []backup
+	for _, e := range entries {
+		m := pattern.FindStringSubmatch(e.Name())
+		if m == nil || !e.Type().IsRegular() {
+			continue
+		}
+		b := backup{path: filepath.Join(filepath.Dir(path), e.Name()), stamp: m[1]}
+		if m[2] != "" {
+			if b.seq, err = strconv.Atoi(m[2]); err != nil {
+				continue
+			}
+		}
+		backups = append(backups, b)
+	}
+	// ".10"이 ".2"보다 앞에 오지 않도록 번호는 숫자로 비교
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].stamp != backups[j].stamp {
+			return backups[i].stamp < backups[j].stamp
+		}
+		return backups[i].seq < backups[j].seq
+	})
+	return backups
+}
+
+// Sync: 버퍼에 남은 내용을 디스크에 기록합니다.
+func (rf *RotatingFile) Sync() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.file == nil {
+		return nil
+	}
+	return rf.file.Sync()
+}
+
+// Close: 로그 파일을 닫습니다.
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	rf.closed = true
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
@@ -0,0 +1,78 @@
+package accesslog
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter: 상태 코드와 전송 바이트 수를 기록하는 http.ResponseWriter 래퍼
+// http.Flusher, http.Hijacker를 그대로 전달하며 Unwrap으로 http.ResponseController도 지원합니다.
+type ResponseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// NewResponseWriter: w를 감싼 ResponseWriter를 생성합니다.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+// Status: 응답 상태 코드 (WriteHeader 없이 Write만 했다면 200)
+func (rw *ResponseWriter) Status() int {
+	if rw.status == 0 {
+		return http.StatusOK
+	}
+	return rw.status
+}
+
+// BytesWritten: 응답 본문으로 전송한 바이트 수
+func (rw *ResponseWriter) BytesWritten() int64 {
+	return rw.bytes
+}
+
+func (rw *ResponseWriter) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *ResponseWriter) Write(p []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.Write(p)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Flush: 스트리밍 응답(SSE 등)을 위해 하위 Flusher를 호출합니다.
+func (rw *ResponseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack: 연결 가로채기(WebSocket 등)를 지원합니다. 가로챈 연결은 101로 기록됩니다.
+func (rw *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("accesslog: underlying ResponseWriter does not support hijacking")
+	}
+	conn, buf, err := h.Hijack()
+	if err == nil && rw.status == 0 {
+		rw.status = http.StatusSwitchingProtocols
+	}
+	return conn, buf, err
+}
+
+// Unwrap: http.ResponseController가 원래의 ResponseWriter에 접근할 수 있도록 합니다.
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
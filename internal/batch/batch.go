@@ -0,0 +1,88 @@
+// Package batch는 일괄 처리 요청을 워커 풀로 병렬 처리하고 결과를 순서대로 또는 완료되는 대로 전달합니다.
+package batch
+
+import (
+	"context"
+	"sync"
+)
+
+// Item: 입력 순서(Index)가 붙은 작업 하나
+type Item[T any] struct {
+	Index int
+	Value T
+}
+
+// Process: in 채널로 들어오는 작업을 workers개의 고루틴으로 처리합니다.
+// ordered이면 결과를 입력 순서대로, 아니면 완료되는 대로 emit에 전달합니다.
+// emit이 오류를 반환하면(예: 클라이언트 연결 끊김) 남은 작업을 취소하고 그 오류를 반환합니다.
+// in 채널은 호출자가 닫아야 하며, 취소된 경우 남은 입력은 버려집니다.
+func Process[T, R any](ctx context.Context, in <-chan Item[T], workers int, ordered bool,
+	fn func(ctx context.Context, v T) R, emit func(index int, r R) error) error {
+
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		index int
+		value R
+	}
+	out := make(chan result, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range in {
+				if ctx.Err() != nil {
+					continue // 입력 채널이 닫힐 때까지 비움
+				}
+				r := fn(ctx, item.Value)
+				select {
+				case out <- result{item.Index, r}:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	// ordered 모드: 아직 차례가 아닌 결과를 보관하는 재정렬 버퍼
+	pending := make(map[int]R)
+	next := 0
+	var emitErr error
+	for res := range out {
+		if emitErr != nil {
+			continue
+		}
+		if !ordered {
+			emitErr = emit(res.index, res.value)
+		} else {
+			pending[res.index] = res.value
+			for {
+				v, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				if emitErr = emit(next, v); emitErr != nil {
+					break
+				}
+				next++
+			}
+		}
+		if emitErr != nil {
+			cancel()
+		}
+	}
+	if emitErr != nil {
+		return emitErr
+	}
+	return ctx.Err()
+}
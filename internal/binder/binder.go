@@ -0,0 +1,283 @@
+// Package binder는 쿼리/폼 파라미터(url.Values)를 구조체 태그에 따라 Go 구조체로 변환하고 검증합니다.
+//
+// 태그 형식:
+//
+//	type calcParams struct {
+//		Mode string   `param:"mode" default:"int" validate:"oneof=int|big"`
+//		Var1 int      `param:"var1" validate:"required,min=-1000,max=1000"`
+//		Tags []string `param:"tag"` // 같은 키가 반복되면 모두 수집
+//	}
+//
+// 지원 타입: string, bool, int/int8..int64, uint/uint8..uint64, float32/float64 및 이들의 슬라이스
+// 검증 규칙: required, min=N, max=N (숫자는 값, 문자열은 길이), oneof=a|b|c
+package binder
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldError: 파라미터 하나의 오류
+type FieldError struct {
+	Param string // 파라미터 이름 (예: var1)
+	Msg   string
+	Err   error // 원인 (예: strconv.ErrRange), 없으면 nil
+}
+
+func (e *FieldError) Error() string {
+	return e.Param + ": " + e.Msg
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError: 모든 파라미터 오류를 모은 오류
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Error()
+	}
+	return "Invalid parameter(s): " + strings.Join(msgs, "; ")
+}
+
+// Unwrap: errors.Is/As로 개별 FieldError와 그 원인을 검사할 수 있게 합니다.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, f := range e.Fields {
+		errs[i] = f
+	}
+	return errs
+}
+
+// Option: Bind 동작을 바꾸는 옵션
+type Option func(*options)
+
+type options struct {
+	aliases map[string][]string
+}
+
+// WithAlias: param 이름의 값이 없을 때 대신 찾아볼 파라미터 이름을 지정합니다.
+// (예: 멤버십 API에서 "value" 대신 회원 ID를 키로 보내는 클라이언트 지원)
+func WithAlias(param string, alias string) Option {
+	return func(o *options) {
+		if o.aliases == nil {
+			o.aliases = make(map[string][]string)
+		}
+		o.aliases[param] = append(o.aliases[param], alias)
+	}
+}
+
+// Bind: values를 dst(구조체 포인터)에 채우고 태그 규칙을 검증합니다.
+// 오류가 있는 모든 필드를 *ValidationError 하나로 모아 반환합니다.
+func Bind(values url.Values, dst any, opts ...Option) error {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binder: dst must be a pointer to a struct, got %T", dst)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	verr := &ValidationError{}
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name, ok := field.Tag.Lookup("param")
+		if !ok || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		rules, err := parseRules(field.Tag.Get("validate"))
+		if err != nil {
+			return fmt.Errorf("binder: field %s: %w", field.Name, err)
+		}
+
+		raw, present := lookup(values, name, o.aliases[name])
+		if !present {
+			if def, ok := field.Tag.Lookup("default"); ok {
+				raw, present = []string{def}, true
+			}
+		}
+		if !present {
+			if rules.required {
+				verr.Fields = append(verr.Fields, &FieldError{Param: name, Msg: "is required"})
+			}
+			continue
+		}
+
+		if fe := setField(rv.Field(i), name, raw, rules); fe != nil {
+			verr.Fields = append(verr.Fields, fe)
+		}
+	}
+
+	if len(verr.Fields) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// lookup: 이름 또는 별칭으로 값 목록을 찾습니다. 빈 문자열 하나만 있는 경우는 없는 것으로 봅니다.
+func lookup(values url.Values, name string, aliases []string) ([]string, bool) {
+	for _, key := range append([]string{name}, aliases...) {
+		if vs := values[key]; len(vs) > 0 && !(len(vs) == 1 && vs[0] == "") {
+			return vs, true
+		}
+	}
+	return nil, false
+}
+
+// setField: 문자열 값을 필드 타입으로 변환하고 검증합니다.
+func setField(fv reflect.Value, name string, raw []string, rules rules) *FieldError {
+	if fv.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(fv.Type(), len(raw), len(raw))
+		for i, s := range raw {
+			if fe := setScalar(slice.Index(i), name, s, rules); fe != nil {
+				if len(raw) > 1 {
+					fe.Msg = fmt.Sprintf("item %d %s", i+1, fe.Msg)
+				}
+				return fe
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	if len(raw) > 1 {
+		return &FieldError{Param: name, Msg: fmt.Sprintf("must be given once (got %d values)", len(raw))}
+	}
+	return setScalar(fv, name, raw[0], rules)
+}
+
+func setScalar(fv reflect.Value, name, s string, rules rules) *FieldError {
+	switch fv.Kind() {
+	case reflect.String:
+		if rules.min != nil && float64(len(s)) < *rules.min {
+			return &FieldError{Param: name, Msg: fmt.Sprintf("must be at least %g characters", *rules.min)}
+		}
+		if rules.max != nil && float64(len(s)) > *rules.max {
+			return &FieldError{Param: name, Msg: fmt.Sprintf("must be at most %g characters", *rules.max)}
+		}
+		if len(rules.oneof) > 0 && !contains(rules.oneof, s) {
+			return &FieldError{Param: name, Msg: fmt.Sprintf("must be one of %s (got %q)", strings.Join(rules.oneof, ", "), s)}
+		}
+		fv.SetString(s)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return &FieldError{Param: name, Msg: fmt.Sprintf("must be a boolean (got %q)", s), Err: err}
+		}
+		fv.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, fv.Type().Bits())
+		if err != nil {
+			return numError(name, s, "an integer", fv.Type(), err)
+		}
+		if fe := checkRange(name, float64(n), rules); fe != nil {
+			return fe
+		}
+		fv.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(s), 10, fv.Type().Bits())
+		if err != nil {
+			return numError(name, s, "a non-negative integer", fv.Type(), err)
+		}
+		if fe := checkRange(name, float64(n), rules); fe != nil {
+			return fe
+		}
+		fv.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), fv.Type().Bits())
+		if err != nil {
+			return numError(name, s, "a number", fv.Type(), err)
+		}
+		if fe := checkRange(name, f, rules); fe != nil {
+			return fe
+		}
+		fv.SetFloat(f)
+
+	default:
+		return &FieldError{Param: name, Msg: fmt.Sprintf("has unsupported type %s", fv.Type())}
+	}
+	return nil
+}
+
+func numError(name, s, what string, t reflect.Type, err error) *FieldError {
+	if errors.Is(err, strconv.ErrRange) {
+		return &FieldError{Param: name, Msg: fmt.Sprintf("is out of range for %s (got %q)", t, s), Err: strconv.ErrRange}
+	}
+	return &FieldError{Param: name, Msg: fmt.Sprintf("must be %s (got %q)", what, s), Err: strconv.ErrSyntax}
+}
+
+func checkRange(name string, v float64, rules rules) *FieldError {
+	if rules.min != nil && v < *rules.min {
+		return &FieldError{Param: name, Msg: fmt.Sprintf("must be >= %g", *rules.min)}
+	}
+	if rules.max != nil && v > *rules.max {
+		return &FieldError{Param: name, Msg: fmt.Sprintf("must be <= %g", *rules.max)}
+	}
+	if len(rules.oneof) > 0 && !contains(rules.oneof, strconv.FormatFloat(v, 'g', -1, 64)) {
+		return &FieldError{Param: name, Msg: fmt.Sprintf("must be one of %s", strings.Join(rules.oneof, ", "))}
+	}
+	return nil
+}
+
+// rules: validate 태그를 해석한 검증 규칙
+type rules struct {
+	required bool
+	min, max *float64
+	oneof    []string
+}
+
+func parseRules(tag string) (rules, error) {
+	var r rules
+	if tag == "" {
+		return r, nil
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		key, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch key {
+		case "required":
+			r.required = true
+		case "min", "max":
+			v, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return r, fmt.Errorf("invalid %s rule %q", key, arg)
+			}
+			if key == "min" {
+				r.min = &v
+			} else {
+				r.max = &v
+			}
+		case "oneof":
+			r.oneof = strings.Split(arg, "|")
+		default:
+			return r, fmt.Errorf("unknown validation rule %q", key)
+		}
+	}
+	return r, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
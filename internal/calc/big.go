@@ -0,0 +1,377 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// BigLimits: 정밀도 모드(mode=big)에서 서버 자원을 보호하기 위한 제한값
+type BigLimits struct {
+	MaxOperandLen int  // 피연산자(숫자 리터럴) 하나의 최대 길이 (문자 수)
+	MaxResultBits int  // 중간/최종 결과의 분자+분모 최대 비트 수
+	FloatPrec     uint // 근사값(big.Float) 계산에 사용하는 정밀도 (비트)
+	FloatDigits   int  // 근사값 출력 시 유효 숫자 수
+}
+
+// DefaultBigLimits: 기본 제한값 (약 1000자리 피연산자, 약 20000자리 결과)
+var DefaultBigLimits = BigLimits{
+	MaxOperandLen: 1000,
+	MaxResultBits: 1 << 16,
+	FloatPrec:     256,
+	FloatDigits:   50,
+}
+
+// BigValue: 정밀도 모드의 값
+// 정수/유리수는 big.Rat로 정확하게 보관하고, 정확히 표현할 수 없는 결과(예: sqrt(2))는
+// big.Float 근사값으로 보관합니다.
+type BigValue struct {
+	rat *big.Rat
+	flt *big.Float
+}
+
+// Exact: 값이 정확한 정수/유리수이면 true
+func (v *BigValue) Exact() bool {
+	return v.flt == nil
+}
+
+// String: 정수는 "123", 유리수는 "1/3", 근사값은 유효 숫자 FloatDigits 자리로 표현합니다.
+func (v *BigValue) String() string {
+	return v.Format(DefaultBigLimits)
+}
+
+// Format: 주어진 제한값의 FloatDigits를 사용해 값을 문자열로 변환합니다.
+func (v *BigValue) Format(lim BigLimits) string {
+	if v.flt != nil {
+		return v.flt.Text('g', lim.FloatDigits)
+	}
+	return v.rat.RatString()
+}
+
+// float: 값을 big.Float로 변환합니다.
+func (v *BigValue) float(prec uint) *big.Float {
+	if v.flt != nil {
+		return v.flt
+	}
+	return new(big.Float).SetPrec(prec).SetRat(v.rat)
+}
+
+// ParseBigOperand: var1/var2 같은 피연산자를 정수, 유리수("1/3") 또는 소수("1.5e3")로 파싱합니다.
+func ParseBigOperand(s string, lim BigLimits) (*BigValue, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > lim.MaxOperandLen {
+		return nil, fmt.Errorf("operand is too long (%d characters, max %d)", len(s), lim.MaxOperandLen)
+	}
+	if err := checkExponent(s, lim); err != nil {
+		return nil, err
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid operand %q: expected an integer, rational or decimal number", s)
+	}
+	v := &BigValue{rat: r}
+	if err := checkBigSize(v, lim); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// MulBig: 두 값을 정확하게 곱합니다. (정밀도 모드의 simpleCalc)
+func MulBig(x, y *BigValue, lim BigLimits) (*BigValue, error) {
+	return bigBinary("*", x, y, lim)
+}
+
+// EvaluateBig: 수식을 정밀도 모드로 파싱하고 평가합니다.
+func EvaluateBig(src string, lim BigLimits) (*BigValue, error) {
+	node, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return EvalBig(node, lim)
+}
+
+// EvalBig: 구문 트리를 big.Rat/big.Float로 평가합니다.
+func EvalBig(node Node, lim BigLimits) (*BigValue, error) {
+	switch n := node.(type) {
+	case *NumberLit:
+		if len(n.Text) > lim.MaxOperandLen {
+			return nil, &EvalError{Pos: n.Pos, Msg: fmt.Sprintf("number is too long (%d characters, max %d)", len(n.Text), lim.MaxOperandLen)}
+		}
+		if err := checkExponent(n.Text, lim); err != nil {
+			return nil, wrapPos(n.Pos, err)
+		}
+		r, ok := new(big.Rat).SetString(n.Text)
+		if !ok {
+			return nil, &EvalError{Pos: n.Pos, Msg: fmt.Sprintf("invalid number %s", n.Text)}
+		}
+		v := &BigValue{rat: r}
+		return v, wrapPos(n.Pos, checkBigSize(v, lim))
+
+	case *UnaryExpr:
+		x, err := EvalBig(n.X, lim)
+		if err != nil {
+			return nil, err
+		}
+		if n.Op != "-" {
+			return x, nil
+		}
+		if x.flt != nil {
+			return &BigValue{flt: new(big.Float).Neg(x.flt)}, nil
+		}
+		return &BigValue{rat: new(big.Rat).Neg(x.rat)}, nil
+
+	case *BinaryExpr:
+		x, err := EvalBig(n.X, lim)
+		if err != nil {
+			return nil, err
+		}
+		y, err := EvalBig(n.Y, lim)
+		if err != nil {
+			return nil, err
+		}
+		v, err := bigBinary(n.Op, x, y, lim)
+		return v, wrapPos(n.Pos, err)
+
+	case *CallExpr:
+		if _, err := checkCall(n); err != nil {
+			return nil, err
+		}
+		args := make([]*BigValue, len(n.Args))
+		for i, a := range n.Args {
+			v, err := EvalBig(a, lim)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+		v, err := bigCall(n.Name, args, lim)
+		return v, wrapPos(n.Pos, err)
+
+	default:
+		return nil, fmt.Errorf("unsupported node type %T", node)
+	}
+}
+
+// wrapPos: 위치 정보가 없는 오류를 EvalError로 감쌉니다.
+func wrapPos(pos int, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*EvalError); ok {
+		return err
+	}
+	return &EvalError{Pos: pos, Msg: err.Error()}
+}
+
+// checkBigSize: 결과 크기가 MaxResultBits를 넘지 않는지 검사합니다.
+func checkBigSize(v *BigValue, lim BigLimits) error {
+	if v.rat != nil && v.rat.Num().BitLen()+v.rat.Denom().BitLen() > lim.MaxResultBits {
+		return fmt.Errorf("result exceeds the maximum size of %d bits", lim.MaxResultBits)
+	}
+	if v.flt != nil && v.flt.IsInf() {
+		return fmt.Errorf("result is infinite")
+	}
+	return nil
+}
+
+// checkExponent: big.Rat.SetString이 숫자를 펼치기 전에 지수부만 보고 크기를 검사합니다.
+// "1e9999999"처럼 짧은 리터럴도 펼치면 수 MB의 정수가 되므로, 가수의 자릿수로 줄일 수 있는 만큼을 빼고도
+// MaxResultBits를 넘으면 거부합니다. 경계에 걸친 값은 SetString 뒤의 checkBigSize가 정확히 검사합니다.
+func checkExponent(s string, lim BigLimits) error {
+	if exponentBits(s) > int64(lim.MaxResultBits)+4*int64(len(s)) {
+		return fmt.Errorf("result exceeds the maximum size of %d bits", lim.MaxResultBits)
+	}
+	return nil
+}
+
+// exponentBits: 소수 표기 s의 지수부(10진수 e, 2진수 p)가 만드는 크기를 비트 수로 어림합니다. 지수가 없으면 0입니다.
+func exponentBits(s string) int64 {
+	s = strings.TrimLeft(s, "+-")
+	if strings.Contains(s, "/") {
+		return 0 // 분수("a/b")에는 지수를 쓸 수 없음
+	}
+	markers := "eEpP"
+	if len(s) > 1 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		markers = "pP" // 16진수의 e는 숫자
+	}
+	i := strings.LastIndexAny(s, markers)
+	if i < 0 {
+		return 0
+	}
+	e, err := strconv.ParseInt(s[i+1:], 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return math.MaxInt64
+	}
+	if err != nil {
+		return 0 // 형식 오류는 SetString이 거부
+	}
+	e = absInt64(e)
+	if s[i] == 'p' || s[i] == 'P' {
+		return e
+	}
+	if e > math.MaxInt64/4 {
+		return math.MaxInt64
+	}
+	return e*10/3 + 1 // log2(10) < 10/3
+}
+
+// bigBinary: 정밀도 모드의 이항 연산
+func bigBinary(op string, x, y *BigValue, lim BigLimits) (*BigValue, error) {
+	if (op == "/" || op == "%") && bigSign(y) == 0 {
+		if op == "/" {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return nil, fmt.Errorf("modulo by zero")
+	}
+	if op == "^" {
+		return bigPow(x, y, lim)
+	}
+
+	var v *BigValue
+	if x.Exact() && y.Exact() {
+		r := new(big.Rat)
+		switch op {
+		case "+":
+			r.Add(x.rat, y.rat)
+		case "-":
+			r.Sub(x.rat, y.rat)
+		case "*":
+			r.Mul(x.rat, y.rat)
+		case "/":
+			r.Quo(x.rat, y.rat)
+		case "%":
+			// x - y*trunc(x/y): 부호는 x를 따릅니다. (math.Mod와 동일)
+			q := new(big.Rat).Quo(x.rat, y.rat)
+			t := new(big.Int).Quo(q.Num(), q.Denom())
+			r.Sub(x.rat, new(big.Rat).Mul(y.rat, new(big.Rat).SetInt(t)))
+		default:
+			return nil, fmt.Errorf("unknown operator %q", op)
+		}
+		v = &BigValue{rat: r}
+	} else {
+		a, b := x.float(lim.FloatPrec), y.float(lim.FloatPrec)
+		f := new(big.Float).SetPrec(lim.FloatPrec)
+		switch op {
+		case "+":
+			f.Add(a, b)
+		case "-":
+			f.Sub(a, b)
+		case "*":
+			f.Mul(a, b)
+		case "/":
+			f.Quo(a, b)
+		case "%":
+			q := new(big.Float).SetPrec(lim.FloatPrec).Quo(a, b)
+			t, _ := q.Int(nil)
+			f.Sub(a, new(big.Float).SetPrec(lim.FloatPrec).Mul(b, new(big.Float).SetInt(t)))
+		default:
+			return nil, fmt.Errorf("unknown operator %q", op)
+		}
+		v = &BigValue{flt: f}
+	}
+	return v, checkBigSize(v, lim)
+}
+
+// bigPow: 거듭제곱. 지수가 정수이면 정확하게, 그렇지 않으면 float64 근사로 계산합니다.
+func bigPow(x, y *BigValue, lim BigLimits) (*BigValue, error) {
+	if x.Exact() && y.Exact() && y.rat.IsInt() {
+		exp := y.rat.Num()
+		base := x.rat
+		if base.Sign() == 0 {
+			if exp.Sign() < 0 {
+				return nil, fmt.Errorf("zero raised to a negative power")
+			}
+			if exp.Sign() == 0 {
+				return &BigValue{rat: big.NewRat(1, 1)}, nil
+			}
+			return &BigValue{rat: new(big.Rat)}, nil
+		}
+		// 계산 전에 결과 크기를 추정하여 거대한 지수로 인한 DoS를 방지
+		// (분자, 분모가 모두 ±1이면 크기가 늘지 않습니다.)
+		growth := int64(base.Num().BitLen() - 1 + base.Denom().BitLen() - 1)
+		if growth > 0 && (!exp.IsInt64() || absInt64(exp.Int64()) > int64(lim.MaxResultBits)/growth) {
+			return nil, fmt.Errorf("result exceeds the maximum size of %d bits", lim.MaxResultBits)
+		}
+		e := new(big.Int).Abs(exp)
+		num := new(big.Int).Exp(base.Num(), e, nil)
+		den := new(big.Int).Exp(base.Denom(), e, nil)
+		if exp.Sign() < 0 {
+			num, den = den, num
+		}
+		v := &BigValue{rat: new(big.Rat).SetFrac(num, den)}
+		return v, checkBigSize(v, lim)
+	}
+
+	a, _ := x.float(lim.FloatPrec).Float64()
+	b, _ := y.float(lim.FloatPrec).Float64()
+	p := math.Pow(a, b)
+	if math.IsNaN(p) {
+		return nil, fmt.Errorf("result is not a real number")
+	}
+	if math.IsInf(p, 0) {
+		return nil, fmt.Errorf("result overflows the approximate power range")
+	}
+	return &BigValue{flt: new(big.Float).SetPrec(lim.FloatPrec).SetFloat64(p)}, nil
+}
+
+// bigCall: 정밀도 모드의 내장 함수 호출
+func bigCall(name string, args []*BigValue, lim BigLimits) (*BigValue, error) {
+	switch name {
+	case "abs":
+		if bigSign(args[0]) >= 0 {
+			return args[0], nil
+		}
+		return bigBinary("-", &BigValue{rat: new(big.Rat)}, args[0], lim)
+
+	case "min", "max":
+		best := args[0]
+		for _, a := range args[1:] {
+			c := bigCmp(a, best, lim)
+			if (name == "min" && c < 0) || (name == "max" && c > 0) {
+				best = a
+			}
+		}
+		return best, nil
+
+	case "sqrt":
+		x := args[0]
+		if bigSign(x) < 0 {
+			return nil, fmt.Errorf("sqrt() of a negative number")
+		}
+		if x.Exact() {
+			// 분자와 분모가 모두 완전제곱수이면 정확한 결과를 반환
+			n, d := new(big.Int).Sqrt(x.rat.Num()), new(big.Int).Sqrt(x.rat.Denom())
+			if new(big.Int).Mul(n, n).Cmp(x.rat.Num()) == 0 && new(big.Int).Mul(d, d).Cmp(x.rat.Denom()) == 0 {
+				return &BigValue{rat: new(big.Rat).SetFrac(n, d)}, nil
+			}
+		}
+		return &BigValue{flt: new(big.Float).SetPrec(lim.FloatPrec).Sqrt(x.float(lim.FloatPrec))}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+}
+
+func bigSign(v *BigValue) int {
+	if v.flt != nil {
+		return v.flt.Sign()
+	}
+	return v.rat.Sign()
+}
+
+func bigCmp(x, y *BigValue, lim BigLimits) int {
+	if x.Exact() && y.Exact() {
+		return x.rat.Cmp(y.rat)
+	}
+	return x.float(lim.FloatPrec).Cmp(y.float(lim.FloatPrec))
+}
+
+func absInt64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,164 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// EvalError: 평가 중 발생한 오류 (0으로 나누기, 잘못된 함수 호출 등)
+type EvalError struct {
+	Pos int
+	Msg string
+}
+
+func (e *EvalError) Error() string {
+	return fmt.Sprintf("evaluation error at position %d: %s", e.Pos, e.Msg)
+}
+
+// funcSpec: 내장 함수 정의
+type funcSpec struct {
+	minArgs int
+	maxArgs int // -1이면 개수 제한 없음
+	call    func(args []float64) float64
+}
+
+var builtins = map[string]funcSpec{
+	"abs":  {1, 1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"sqrt": {1, 1, func(a []float64) float64 { return math.Sqrt(a[0]) }},
+	"min": {1, -1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Min(m, v)
+		}
+		return m
+	}},
+	"max": {1, -1, func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Max(m, v)
+		}
+		return m
+	}},
+}
+
+// checkCall: 함수 이름과 인자 개수를 검사합니다.
+func checkCall(n *CallExpr) (funcSpec, error) {
+	spec, ok := builtins[n.Name]
+	if !ok {
+		return funcSpec{}, &EvalError{Pos: n.Pos, Msg: fmt.Sprintf("unknown function %q", n.Name)}
+	}
+	if len(n.Args) < spec.minArgs || (spec.maxArgs >= 0 && len(n.Args) > spec.maxArgs) {
+		want := fmt.Sprintf("%d", spec.minArgs)
+		if spec.maxArgs < 0 {
+			want = fmt.Sprintf("at least %d", spec.minArgs)
+		}
+		return funcSpec{}, &EvalError{Pos: n.Pos, Msg: fmt.Sprintf("%s() takes %s argument(s), got %d", n.Name, want, len(n.Args))}
+	}
+	return spec, nil
+}
+
+// Eval: 구문 트리를 float64로 평가합니다.
+func Eval(node Node) (float64, error) {
+	switch n := node.(type) {
+	case *NumberLit:
+		v, err := strconv.ParseFloat(n.Text, 64)
+		if err != nil {
+			return 0, &EvalError{Pos: n.Pos, Msg: fmt.Sprintf("number %s is out of range", n.Text)}
+		}
+		return v, nil
+
+	case *UnaryExpr:
+		x, err := Eval(n.X)
+		if err != nil {
+			return 0, err
+		}
+		if n.Op == "-" {
+			return -x, nil
+		}
+		return x, nil
+
+	case *BinaryExpr:
+		x, err := Eval(n.X)
+		if err != nil {
+			return 0, err
+		}
+		y, err := Eval(n.Y)
+		if err != nil {
+			return 0, err
+		}
+		return applyBinary(n, x, y)
+
+	case *CallExpr:
+		spec, err := checkCall(n)
+		if err != nil {
+			return 0, err
+		}
+		args := make([]float64, len(n.Args))
+		for i, a := range n.Args {
+			if args[i], err = Eval(a); err != nil {
+				return 0, err
+			}
+		}
+		if n.Name == "sqrt" && args[0] < 0 {
+			return 0, &EvalError{Pos: n.Pos, Msg: "sqrt() of a negative number"}
+		}
+		return checkResult(n.Pos, spec.call(args))
+
+	default:
+		return 0, fmt.Errorf("unsupported node type %T", node)
+	}
+}
+
+// applyBinary: 이항 연산을 수행합니다.
+func applyBinary(n *BinaryExpr, x, y float64) (float64, error) {
+	var v float64
+	switch n.Op {
+	case "+":
+		v = x + y
+	case "-":
+		v = x - y
+	case "*":
+		v = x * y
+	case "/":
+		if y == 0 {
+			return 0, &EvalError{Pos: n.Pos, Msg: "division by zero"}
+		}
+		v = x / y
+	case "%":
+		if y == 0 {
+			return 0, &EvalError{Pos: n.Pos, Msg: "modulo by zero"}
+		}
+		v = math.Mod(x, y)
+	case "^":
+		v = math.Pow(x, y)
+	default:
+		return 0, &EvalError{Pos: n.Pos, Msg: fmt.Sprintf("unknown operator %q", n.Op)}
+	}
+	return checkResult(n.Pos, v)
+}
+
+// checkResult: 무한대/NaN 결과를 오류로 변환합니다.
+func checkResult(pos int, v float64) (float64, error) {
+	if math.IsInf(v, 0) {
+		return 0, &EvalError{Pos: pos, Msg: "result overflows float64"}
+	}
+	if math.IsNaN(v) {
+		return 0, &EvalError{Pos: pos, Msg: "result is not a real number"}
+	}
+	return v, nil
+}
+
+// Evaluate: 수식 문자열을 파싱하고 평가합니다.
+func Evaluate(src string) (float64, error) {
+	node, err := Parse(src)
+	if err != nil {
+		return 0, err
+	}
+	return Eval(node)
+}
+
+// FormatFloat: 결과값을 가장 짧은 10진 표현으로 변환합니다. (81.0 -> "81")
+func FormatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,150 @@
+// Package calc는 계산기 서버가 사용하는 수식 엔진(토크나이저, 파서, 평가기)을 제공합니다.
+package calc
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind: 토큰 종류
+type TokenKind int
+
+const (
+	TokenEOF    TokenKind = iota
+	TokenNumber           // 123, 1.5, 2e10
+	TokenIdent            // abs, min, max, sqrt
+	TokenOp               // + - * / % ^
+	TokenLParen           // (
+	TokenRParen           // )
+	TokenComma            // ,
+)
+
+// Token: 토크나이저가 만들어 내는 하나의 토큰
+// Pos는 수식 안에서의 문자 위치(1부터 시작)입니다.
+type Token struct {
+	Kind TokenKind
+	Text string
+	Pos  int
+}
+
+// String: 오류 메시지에 사용할 토큰 표현
+func (t Token) String() string {
+	if t.Kind == TokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.Text)
+}
+
+// SyntaxError: 위치 정보를 포함한 구문 오류
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+// Tokenize: 수식 문자열을 토큰 목록으로 분리합니다.
+// 마지막 토큰은 항상 TokenEOF 입니다.
+func Tokenize(src string) ([]Token, error) {
+	var tokens []Token
+	pos := 0 // 문자(rune) 단위 위치
+	i := 0   // 바이트 단위 인덱스
+
+	for i < len(src) {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		pos++
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+
+		case isDigit(r) || r == '.':
+			start, startPos := i, pos
+			n, err := scanNumber(src[i:], startPos)
+			if err != nil {
+				return nil, err
+			}
+			i += n
+			pos += n - 1 // 숫자는 ASCII 문자로만 구성됩니다.
+			tokens = append(tokens, Token{Kind: TokenNumber, Text: src[start:i], Pos: startPos})
+
+		case unicode.IsLetter(r) || r == '_':
+			start, startPos := i, pos
+			i += size
+			for i < len(src) {
+				r, size = utf8.DecodeRuneInString(src[i:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+					break
+				}
+				i += size
+				pos++
+			}
+			tokens = append(tokens, Token{Kind: TokenIdent, Text: src[start:i], Pos: startPos})
+
+		case r == '+' || r == '-' || r == '*' || r == '/' || r == '%' || r == '^':
+			tokens = append(tokens, Token{Kind: TokenOp, Text: string(r), Pos: pos})
+			i += size
+
+		case r == '(':
+			tokens = append(tokens, Token{Kind: TokenLParen, Text: "(", Pos: pos})
+			i += size
+
+		case r == ')':
+			tokens = append(tokens, Token{Kind: TokenRParen, Text: ")", Pos: pos})
+			i += size
+
+		case r == ',':
+			tokens = append(tokens, Token{Kind: TokenComma, Text: ",", Pos: pos})
+			i += size
+
+		default:
+			return nil, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	tokens = append(tokens, Token{Kind: TokenEOF, Pos: pos + 1})
+	return tokens, nil
+}
+
+// scanNumber: 숫자 리터럴(정수, 소수, 지수 표기)의 바이트 길이를 반환합니다.
+func scanNumber(s string, pos int) (int, error) {
+	i := 0
+	digits := 0
+	for i < len(s) && isDigit(rune(s[i])) {
+		i++
+		digits++
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		for i < len(s) && isDigit(rune(s[i])) {
+			i++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return 0, &SyntaxError{Pos: pos, Msg: "malformed number"}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		expDigits := 0
+		for j < len(s) && isDigit(rune(s[j])) {
+			j++
+			expDigits++
+		}
+		if expDigits == 0 {
+			return 0, &SyntaxError{Pos: pos + i, Msg: "malformed exponent in number"}
+		}
+		i = j
+	}
+	return i, nil
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
@@ -0,0 +1,227 @@
+package calc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Node: 구문 트리(AST)의 노드
+type Node interface {
+	// Position: 노드가 시작되는 문자 위치(1부터 시작)
+	Position() int
+	// String: 괄호를 모두 표시한 정규화된 수식 표현
+	String() string
+}
+
+// NumberLit: 숫자 리터럴 (원문 그대로 보관하여 정밀도 모드에서도 사용)
+type NumberLit struct {
+	Pos  int
+	Text string
+}
+
+// UnaryExpr: 단항 연산 (-x, +x)
+type UnaryExpr struct {
+	Pos int
+	Op  string
+	X   Node
+}
+
+// BinaryExpr: 이항 연산 (x + y 등)
+type BinaryExpr struct {
+	Pos  int // 연산자의 위치
+	Op   string
+	X, Y Node
+}
+
+// CallExpr: 함수 호출 (abs(x), min(x, y, ...) 등)
+type CallExpr struct {
+	Pos  int
+	Name string
+	Args []Node
+}
+
+func (n *NumberLit) Position() int  { return n.Pos }
+func (n *UnaryExpr) Position() int  { return n.Pos }
+func (n *BinaryExpr) Position() int { return n.Pos }
+func (n *CallExpr) Position() int   { return n.Pos }
+
+func (n *NumberLit) String() string { return n.Text }
+func (n *UnaryExpr) String() string { return "(" + n.Op + n.X.String() + ")" }
+func (n *BinaryExpr) String() string {
+	return "(" + n.X.String() + " " + n.Op + " " + n.Y.String() + ")"
+}
+func (n *CallExpr) String() string {
+	args := make([]string, len(n.Args))
+	for i, a := range n.Args {
+		args[i] = a.String()
+	}
+	return n.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// 문법 (우선순위가 낮은 것부터):
+//
+//	expr    := term (("+" | "-") term)*
+//	term    := unary (("*" | "/" | "%") unary)*
+//	unary   := ("-" | "+") unary | power
+//	power   := primary ("^" unary)?          // 오른쪽 결합
+//	primary := NUMBER | IDENT "(" args ")" | "(" expr ")"
+//	args    := expr ("," expr)*
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+// Parse: 수식 문자열을 구문 트리로 변환합니다.
+func Parse(src string) (Node, error) {
+	tokens, err := Tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, &SyntaxError{Pos: 1, Msg: "empty expression"}
+	}
+
+	p := &parser{tokens: tokens}
+	node, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.Kind != TokenEOF {
+		return nil, &SyntaxError{Pos: tok.Pos, Msg: fmt.Sprintf("unexpected %s after expression", tok)}
+	}
+	return node, nil
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() Token {
+	tok := p.tokens[p.pos]
+	if tok.Kind != TokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) isOp(ops ...string) bool {
+	tok := p.peek()
+	if tok.Kind != TokenOp {
+		return false
+	}
+	for _, op := range ops {
+		if tok.Text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseExpr() (Node, error) {
+	x, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+", "-") {
+		op := p.next()
+		y, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		x = &BinaryExpr{Pos: op.Pos, Op: op.Text, X: x, Y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseTerm() (Node, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*", "/", "%") {
+		op := p.next()
+		y, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		x = &BinaryExpr{Pos: op.Pos, Op: op.Text, X: x, Y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.isOp("-", "+") {
+		op := p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Pos: op.Pos, Op: op.Text, X: x}, nil
+	}
+	return p.parsePower()
+}
+
+func (p *parser) parsePower() (Node, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("^") {
+		op := p.next()
+		// 지수 부분은 unary부터 다시 파싱하여 2^-1, 2^3^2(=2^9)를 지원
+		y, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		x = &BinaryExpr{Pos: op.Pos, Op: op.Text, X: x, Y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	switch tok.Kind {
+	case TokenNumber:
+		return &NumberLit{Pos: tok.Pos, Text: tok.Text}, nil
+
+	case TokenIdent:
+		if p.peek().Kind != TokenLParen {
+			return nil, &SyntaxError{Pos: tok.Pos, Msg: fmt.Sprintf("unknown identifier %q (functions must be called with parentheses)", tok.Text)}
+		}
+		lparen := p.next()
+		var args []Node
+		if p.peek().Kind != TokenRParen {
+			for {
+				arg, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if p.peek().Kind != TokenComma {
+					break
+				}
+				p.next()
+			}
+		}
+		if closing := p.next(); closing.Kind != TokenRParen {
+			return nil, &SyntaxError{Pos: closing.Pos, Msg: fmt.Sprintf("expected ')' to close call opened at position %d, found %s", lparen.Pos, closing)}
+		}
+		return &CallExpr{Pos: tok.Pos, Name: tok.Text, Args: args}, nil
+
+	case TokenLParen:
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.Kind != TokenRParen {
+			return nil, &SyntaxError{Pos: closing.Pos, Msg: fmt.Sprintf("expected ')' to close '(' at position %d, found %s", tok.Pos, closing)}
+		}
+		return x, nil
+
+	case TokenEOF:
+		return nil, &SyntaxError{Pos: tok.Pos, Msg: "unexpected end of expression, expected a number, function or '('"}
+
+	default:
+		return nil, &SyntaxError{Pos: tok.Pos, Msg: fmt.Sprintf("unexpected %s, expected a number, function or '('", tok)}
+	}
+}
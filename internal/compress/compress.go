@@ -0,0 +1,333 @@
+// Package compress는 Accept-Encoding 협상에 따라 응답 본문을 gzip 또는 deflate로 압축하는 미들웨어입니다.
+// 작은 응답과 이미 압축된 형식(이미지, zip 등)은 그대로 보내며, Flush를 호출하는 스트리밍 응답(SSE, NDJSON)은
+// Flush마다 압축 스트림을 비워 클라이언트가 바로 받을 수 있게 합니다.
+package compress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultMinSize: 이보다 작은 응답은 압축하지 않음 (압축 이득보다 헤더/CPU 비용이 큼)
+const DefaultMinSize = 1024
+
+// 지원하는 콘텐츠 코딩 (Accept-Encoding의 q 값이 같으면 앞쪽을 우선)
+const (
+	Gzip    = "gzip"
+	Deflate = "deflate" // RFC 9110: zlib 형식(RFC 1950)
+)
+
+// Codings: 서버가 지원하는 코딩
+var Codings = []string{Gzip, Deflate}
+
+// skipTypes: 이미 압축되어 있어 다시 압축해도 줄지 않는 Content-Type (접두사 비교)
+var skipTypes = []string{
+	"image/", "video/", "audio/", "font/woff",
+	"application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+	"application/x-bzip2", "application/x-xz", "application/x-7z-compressed", "application/x-rar-compressed",
+	"application/pdf", "application/octet-stream",
+}
+
+// compressibleType: Content-Type이 압축할 만한 형식인지 (SVG는 텍스트이므로 압축)
+func compressibleType(contentType string) bool {
+	ct := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	if ct == "image/svg+xml" {
+		return true
+	}
+	for _, t := range skipTypes {
+		if strings.HasPrefix(ct, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Negotiate: Accept-Encoding 헤더에서 q 값이 가장 높은 지원 코딩을 고릅니다.
+// 헤더가 없거나 허용되는 코딩이 없으면 ""(identity, 압축 안 함)를 반환합니다.
+func Negotiate(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	qs := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if coding == "" {
+			continue
+		}
+		if coding == "x-gzip" {
+			coding = Gzip
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(strings.TrimSpace(k), "q") {
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+				if err != nil || parsed < 0 || parsed > 1 {
+					parsed = 0 // 잘못된 q 값은 허용하지 않는 것으로 취급
+				}
+				q = parsed
+			}
+		}
+		qs[coding] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, c := range Codings {
+		q, ok := qs[c]
+		if !ok {
+			q = qs["*"] // 명시하지 않은 코딩은 "*"의 q 값 (없으면 0)
+		}
+		if q > bestQ {
+			best, bestQ = c, q
+		}
+	}
+	return best
+}
+
+// Config: 압축 설정
+type Config struct {
+	MinSize int // 이보다 작은 응답은 압축하지 않음 (0이면 DefaultMinSize)
+	Level   int // 압축 수준 1(빠름)~9(작음), 0이면 flate.DefaultCompression
+}
+
+// encoder: gzip.Writer와 zlib.Writer의 공통 동작
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// Compressor: 코딩별 인코더를 재사용하는 압축 미들웨어
+type Compressor struct {
+	minSize int
+	pools   map[string]*sync.Pool
+}
+
+// New: 설정을 검증하고 Compressor를 만듭니다.
+func New(cfg Config) (*Compressor, error) {
+	level := cfg.Level
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	if level != flate.DefaultCompression && (level < flate.BestSpeed || level > flate.BestCompression) {
+		return nil, fmt.Errorf("compression level %d out of range 1-9", cfg.Level)
+	}
+	minSize := cfg.MinSize
+	if minSize <= 0 {
+		minSize = DefaultMinSize
+	}
+	newPool := func(mk func() (encoder, error)) *sync.Pool {
+		return &sync.Pool{New: func() any {
+			enc, _ := mk() // 수준은 위에서 검증했으므로 오류 없음
+			return enc
+		}}
+	}
+	return &Compressor{
+		minSize: minSize,
+		pools: map[string]*sync.Pool{
+			Gzip:    newPool(func() (encoder, error) { return gzip.NewWriterLevel(io.Discard, level) }),
+			Deflate: newPool(func() (encoder, error) { return zlib.NewWriterLevel(io.Discard, level) }),
+		},
+	}, nil
+}
+
+// Middleware: 클라이언트가 허용하면 응답을 압축합니다. 압축 여부와 관계없이 "Vary: Accept-Encoding"을 붙입니다.
+func (c *Compressor) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// WebSocket 등 프로토콜 전환 요청은 본문이 없으므로 감싸지 않음
+		if r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		coding := Negotiate(r.Header.Get("Accept-Encoding"))
+		// HEAD는 본문이 없고, Range 요청은 원래 표현의 바이트 범위를 가리키므로 압축하지 않음
+		if coding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &responseWriter{ResponseWriter: w, c: c, coding: coding}
+		defer cw.finish()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// responseWriter: 본문 앞부분을 MinSize까지 모았다가 압축 여부를 정하는 ResponseWriter
+type responseWriter struct {
+	http.ResponseWriter
+	c      *Compressor
+	coding string
+
+	status   int
+	decided  bool   // 압축 여부를 정하고 헤더를 보냄
+	buf      []byte // 결정 전까지 모아 둔 본문
+	enc      encoder
+	hijacked bool
+}
+
+func (cw *responseWriter) WriteHeader(code int) {
+	switch {
+	case cw.hijacked:
+	case cw.decided:
+		cw.ResponseWriter.WriteHeader(code) // 중복 호출 경고는 하위 ResponseWriter에 맡김
+	case code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols:
+		cw.ResponseWriter.WriteHeader(code) // 1xx 정보 응답은 바로 전달
+	case cw.status == 0:
+		cw.status = code
+	}
+}
+
+func (cw *responseWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	if !cw.decided {
+		cw.buf = append(cw.buf, p...)
+		if len(cw.buf) >= cw.c.minSize {
+			if err := cw.decide(true); err != nil {
+				return 0, err
+			}
+		}
+		return len(p), nil
+	}
+	if cw.enc != nil {
+		return cw.enc.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// decide: 압축 여부를 정하고 헤더와 모아 둔 본문을 보냅니다.
+// large는 본문이 MinSize 이상이거나 길이를 알 수 없는 스트리밍 응답(Flush 호출)인지 나타냅니다.
+func (cw *responseWriter) decide(large bool) error {
+	cw.decided = true
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	h := cw.Header()
+	if h.Get("Content-Type") == "" && len(cw.buf) > 0 && h.Get("Content-Encoding") == "" {
+		// 압축된 바이트로 잘못 추측하지 않도록 원래 본문으로 먼저 정함 (net/http와 같은 스니핑)
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+
+	if cw.shouldCompress(large) {
+		h.Del("Content-Length")
+		h.Del("Accept-Ranges")
+		h.Set("Content-Encoding", cw.coding)
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			// 바이트가 달라지므로 강한 ETag는 약한 ETag로 바꿈 (조건부 요청은 그대로 동작)
+			h.Set("ETag", "W/"+etag)
+		}
+		cw.ResponseWriter.WriteHeader(cw.status)
+		cw.enc = cw.c.pools[cw.coding].Get().(encoder)
+		cw.enc.Reset(cw.ResponseWriter)
+	} else {
+		cw.ResponseWriter.WriteHeader(cw.status)
+	}
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// shouldCompress: 상태 코드, 헤더, 크기로 압축할지 판단합니다.
+func (cw *responseWriter) shouldCompress(large bool) bool {
+	h := cw.Header()
+	switch {
+	case !large:
+		return false
+	case cw.status < 200 || cw.status == http.StatusNoContent || cw.status == http.StatusNotModified ||
+		cw.status == http.StatusPartialContent:
+		return false
+	case h.Get("Content-Encoding") != "":
+		return false // 핸들러가 이미 인코딩함
+	case !compressibleType(h.Get("Content-Type")):
+		return false
+	}
+	if cl := h.Get("Content-Length"); cl != "" {
+		if n, err := strconv.ParseInt(cl, 10, 64); err == nil && n < int64(cw.c.minSize) {
+			return false
+		}
+	}
+	return true
+}
+
+// finish: 핸들러가 끝난 뒤 남은 본문을 보내고 압축 스트림을 닫습니다.
+func (cw *responseWriter) finish() {
+	if cw.hijacked {
+		return
+	}
+	if !cw.decided {
+		if cw.status == 0 && len(cw.buf) == 0 {
+			return // 핸들러가 아무것도 쓰지 않음: net/http가 기본 응답을 보냄
+		}
+		cw.decide(false)
+	}
+	if cw.enc != nil {
+		cw.enc.Close()
+		cw.enc.Reset(io.Discard)
+		cw.c.pools[cw.coding].Put(cw.enc)
+		cw.enc = nil
+	}
+}
+
+// FlushError: 지금까지의 본문을 압축 스트림에서 비우고 전송합니다. (SSE, NDJSON 스트리밍)
+func (cw *responseWriter) FlushError() error {
+	if cw.hijacked {
+		return http.ErrHijacked
+	}
+	if !cw.decided {
+		if err := cw.decide(true); err != nil {
+			return err
+		}
+	}
+	if cw.enc != nil {
+		if err := cw.enc.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Flush: http.Flusher
+func (cw *responseWriter) Flush() {
+	cw.FlushError()
+}
+
+// Hijack: 연결 가로채기를 하위 ResponseWriter에 전달합니다. (헤더를 보낸 뒤에는 불가)
+func (cw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if cw.decided {
+		return nil, nil, errors.New("compress: cannot hijack after the response has started")
+	}
+	conn, brw, err := http.NewResponseController(cw.ResponseWriter).Hijack()
+	if err == nil {
+		cw.hijacked = true
+	}
+	return conn, brw, err
+}
+
+// Unwrap: http.ResponseController가 원래 ResponseWriter에 접근할 수 있도록 합니다.
+func (cw *responseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
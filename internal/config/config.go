@@ -0,0 +1,305 @@
+// Package config는 프로그램 설정을 기본값 → JSON 설정 파일 → 환경 변수 → 명령행 플래그 순서로 겹쳐 적용합니다.
+// 설정 항목은 flag.FlagSet에 등록된 플래그이며, 파일의 키와 환경 변수 이름은 플래그 이름에서 만듭니다.
+//
+//	플래그 -rate-limit 5  =  파일 {"rate-limit": 5}  =  환경 변수 CALC_RATE_LIMIT=5 (접두사 CALC)
+//
+// 설정 파일은 -config 플래그나 <접두사>_CONFIG 환경 변수로 지정합니다.
+// 값의 형식 검사는 각 플래그의 Set이 하므로, 잘못된 값은 어느 출처(파일, 환경 변수)의 어떤 항목인지와 함께 보고됩니다.
+//
+// Watch를 호출하면 SIGHUP을 받을 때마다 파일과 환경 변수를 다시 읽어, 재시작 없이 바꿔도 안전한 항목만 다시 적용합니다.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// FlagName: 설정 파일 경로를 받는 플래그 이름
+const FlagName = "config"
+
+// setting: 파일 또는 환경 변수에서 읽은 값 하나
+type setting struct {
+	value  string
+	source string // 오류 메시지에 쓰는 출처 (예: "config.json", "env CALC_RATE_LIMIT")
+}
+
+// Loader: 하나의 FlagSet에 대한 설정 출처와 적용 상태
+type Loader struct {
+	prefix  string
+	fs      *flag.FlagSet
+	path    string          // -config 플래그 값
+	cmdline map[string]bool // 명령행에서 지정한 플래그 (파일과 환경 변수보다 우선하며 다시 읽을 때도 유지)
+}
+
+// New: prefix(환경 변수 접두사, 예: "CALC")와 fs로 Loader를 만들고 -config 플래그를 등록합니다.
+// 프로그램의 플래그를 모두 등록한 뒤 Load를 호출합니다.
+func New(prefix string, fs *flag.FlagSet) *Loader {
+	l := &Loader{prefix: prefix, fs: fs}
+	fs.StringVar(&l.path, FlagName, "", fmt.Sprintf("JSON config file keyed by flag name (env %s); env %s_<FLAG_NAME> and flags override it", l.EnvName(FlagName), prefix))
+	return l
+}
+
+// EnvName: 플래그 이름에 해당하는 환경 변수 이름 (rate-limit → CALC_RATE_LIMIT)
+func (l *Loader) EnvName(flagName string) string {
+	return l.prefix + "_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// Load: 명령행 인자를 해석한 뒤, 명령행에서 지정하지 않은 플래그에 설정 파일과 환경 변수 값을 적용합니다.
+func (l *Loader) Load(args []string) error {
+	if err := l.fs.Parse(args); err != nil {
+		return err
+	}
+	l.cmdline = make(map[string]bool)
+	l.fs.Visit(func(f *flag.Flag) { l.cmdline[f.Name] = true })
+
+	values, err := l.resolve()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names) // 오류가 여러 개면 항상 같은 항목을 보고
+	for _, name := range names {
+		if l.cmdline[name] {
+			continue
+		}
+		if err := l.fs.Set(name, values[name].value); err != nil {
+			return fmt.Errorf("%s: %s=%q: %w", values[name].source, name, values[name].value, err)
+		}
+	}
+	return nil
+}
+
+// Path: 사용 중인 설정 파일 경로 (없으면 "")
+func (l *Loader) Path() string {
+	if !l.cmdline[FlagName] {
+		if p, ok := os.LookupEnv(l.EnvName(FlagName)); ok {
+			return p
+		}
+	}
+	return l.path
+}
+
+// resolve: 설정 파일과 환경 변수의 값을 플래그 이름별로 모읍니다. (환경 변수가 파일보다 우선)
+func (l *Loader) resolve() (map[string]setting, error) {
+	values := make(map[string]setting)
+	if path := l.Path(); path != "" {
+		if err := l.readFile(path, values); err != nil {
+			return nil, err
+		}
+	}
+	l.fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == FlagName {
+			return
+		}
+		env := l.EnvName(f.Name)
+		if v, ok := os.LookupEnv(env); ok {
+			values[f.Name] = setting{value: v, source: "env " + env}
+		}
+	})
+	return values, nil
+}
+
+// readFile: 플래그 이름을 키로 하는 JSON 객체를 읽습니다.
+// 값은 문자열, 숫자, 불리언이며 배열은 쉼표로 이어 붙입니다. (예: "trusted-proxies": ["10.0.0.0/8", "::1"])
+func (l *Loader) readFile(path string, values map[string]setting) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	for name, msg := range raw {
+		if name == FlagName || l.fs.Lookup(name) == nil {
+			return fmt.Errorf("config %s: unknown setting %q", path, name)
+		}
+		v, err := jsonValue(msg)
+		if err != nil {
+			return fmt.Errorf("config %s: %s: %w", path, name, err)
+		}
+		values[name] = setting{value: v, source: path}
+	}
+	return nil
+}
+
+// jsonValue: JSON 값 하나를 플래그 문자열로 바꿉니다.
+func jsonValue(msg json.RawMessage) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(msg))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, e := range v {
+			switch e := e.(type) {
+			case string:
+				parts = append(parts, e)
+			case json.Number:
+				parts = append(parts, e.String())
+			default:
+				return "", errors.New("list items must be strings or numbers")
+			}
+		}
+		return strings.Join(parts, ","), nil
+	}
+	return "", errors.New("value must be a string, number, boolean or list")
+}
+
+// Reload: 설정 파일과 환경 변수를 다시 읽어 safe에 속한 플래그에 적용한 뒤 apply를 호출하고, 값이 바뀐 플래그 이름을 반환합니다.
+// 명령행에서 지정한 플래그는 그대로 두며, 출처에서 사라진 항목은 기본값으로 돌아갑니다.
+// safe에 없는 항목이 바뀌었으면 재시작이 필요하다고 로그에 남깁니다.
+// 읽기나 apply가 실패하면 플래그를 이전 값으로 되돌리므로 FlagSet은 항상 실제로 적용된 설정과 같습니다.
+// 이때 반환하는 이름은 바꾸려다 되돌린 플래그입니다.
+func (l *Loader) Reload(safe []string, apply func(changed []string) error) ([]string, error) {
+	values, err := l.resolve()
+	if err != nil {
+		return nil, err
+	}
+	isSafe := make(map[string]bool, len(safe))
+	for _, name := range safe {
+		isSafe[name] = true
+	}
+
+	// 먼저 모든 값을 확인하고 바꿀 목록을 만든 뒤 한꺼번에 적용
+	type change struct{ name, value string }
+	var changes []change
+	var firstErr error
+	l.fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == FlagName || l.cmdline[f.Name] || firstErr != nil {
+			return
+		}
+		target := f.DefValue
+		if v, ok := values[f.Name]; ok {
+			target = v.value
+		}
+		canonical, err := canonicalValue(f, target)
+		if err != nil {
+			source := "default"
+			if v, ok := values[f.Name]; ok {
+				source = v.source
+			}
+			firstErr = fmt.Errorf("%s: %s=%q: %w", source, f.Name, target, err)
+			return
+		}
+		if canonical == f.Value.String() { // "5"와 "5.0", "1m"과 "60s"처럼 표기만 다른 값은 변경이 아님
+			return
+		}
+		if !isSafe[f.Name] {
+			log.Printf("config: %s changed to %s; restart to apply", f.Name, redact(f.Name, strconv.Quote(target)))
+			return
+		}
+		changes = append(changes, change{f.Name, target})
+	})
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	var changed []string
+	previous := make(map[string]string, len(changes))
+	for _, c := range changes {
+		previous[c.name] = l.fs.Lookup(c.name).Value.String()
+		l.fs.Set(c.name, c.value) // canonicalValue로 확인한 값
+		changed = append(changed, c.name)
+	}
+	if err := apply(changed); err != nil {
+		for _, name := range changed {
+			l.fs.Set(name, previous[name])
+		}
+		return changed, err
+	}
+	return changed, nil
+}
+
+// describe: 플래그의 현재 값을 "이름=값, ..." 형태로 나열합니다. (비밀 값은 가림)
+func (l *Loader) describe(names []string) string {
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + redact(name, l.fs.Lookup(name).Value.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// redact: 토큰이나 키 목록처럼 로그에 남기면 안 되는 플래그의 값을 가립니다.
+func redact(name, value string) string {
+	if strings.Contains(name, "token") || strings.Contains(name, "password") || strings.HasSuffix(name, "-keys") {
+		return "<redacted>"
+	}
+	return value
+}
+
+// canonicalValue: 플래그 값을 바꾸지 않고 v를 해석해, 적용했을 때 f.Value.String()이 돌려줄 표기를 반환합니다.
+func canonicalValue(f *flag.Flag, v string) (string, error) {
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return v, nil // 직접 만든 flag.Value는 적용할 때 Set에서 확인
+	}
+	probe := flag.NewFlagSet("", flag.ContinueOnError)
+	switch getter.Get().(type) {
+	case bool:
+		probe.Bool("v", false, "")
+	case int:
+		probe.Int("v", 0, "")
+	case int64:
+		probe.Int64("v", 0, "")
+	case uint:
+		probe.Uint("v", 0, "")
+	case uint64:
+		probe.Uint64("v", 0, "")
+	case float64:
+		probe.Float64("v", 0, "")
+	case time.Duration:
+		probe.Duration("v", 0, "")
+	default: // 문자열은 그대로
+		return v, nil
+	}
+	if err := probe.Set("v", v); err != nil {
+		return "", err
+	}
+	return probe.Lookup("v").Value.String(), nil
+}
+
+// Watch: SIGHUP을 받을 때마다 Reload(safe, apply)를 실행하고 실제로 적용된 설정을 로그에 남깁니다.
+// apply는 바뀐 플래그가 없어도 호출되므로, CORS 정책처럼 경로가 같아도 내용이 바뀔 수 있는 파일을 다시 읽을 수 있습니다.
+func (l *Loader) Watch(safe []string, apply func(changed []string) error) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	go func() {
+		for range ch {
+			changed, err := l.Reload(safe, apply)
+			switch {
+			case err != nil && len(changed) > 0:
+				log.Printf("config: reload failed, still in effect: %s: %v", l.describe(changed), err)
+			case err != nil:
+				log.Printf("config: reload failed, keeping current settings: %v", err)
+			case len(changed) > 0:
+				log.Printf("config: reloaded, now in effect: %s", l.describe(changed))
+			default:
+				log.Printf("config: reloaded (no flag changes)")
+			}
+		}
+	}()
+}
@@ -0,0 +1,263 @@
+// Package cors는 다른 출처(origin)의 브라우저 앱이 서버를 호출할 수 있도록 CORS 헤더를 붙이는 미들웨어입니다.
+// 정책은 JSON 설정 파일로 정하며, 사전 요청(preflight, OPTIONS)에는 핸들러를 거치지 않고 직접 응답합니다.
+//
+// 정책 파일 예:
+//
+//	{
+//	  "allowed_origins": ["https://app.example.com", "https://*.example.org", "regex:^http://localhost:[0-9]+$"],
+//	  "allowed_methods": ["GET", "POST", "PUT", "DELETE"],
+//	  "allowed_headers": ["Content-Type", "Authorization"],
+//	  "exposed_headers": ["X-Request-Id"],
+//	  "allow_credentials": true,
+//	  "max_age": 600
+//	}
+package cors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// regexPrefix: 출처를 정규식으로 지정할 때 붙이는 접두사
+const regexPrefix = "regex:"
+
+// DefaultMethods: allowed_methods가 비어 있을 때 허용하는 메서드
+var DefaultMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+// Policy: CORS 정책 (JSON 설정 파일 형식)
+type Policy struct {
+	// AllowedOrigins: 허용할 출처. 정확한 값("https://app.example.com"), 모든 출처("*"),
+	// 와일드카드("https://*.example.com", *는 하위 도메인 하나 이상), 정규식("regex:https://...", 출처 전체와 일치해야 함)
+	AllowedOrigins []string `json:"allowed_origins"`
+	// AllowedMethods: 사전 요청에서 허용할 메서드 (비어 있으면 DefaultMethods)
+	AllowedMethods []string `json:"allowed_methods"`
+	// AllowedHeaders: 사전 요청에서 허용할 요청 헤더 ("*"이면 요청한 헤더를 모두 허용)
+	AllowedHeaders []string `json:"allowed_headers"`
+	// ExposedHeaders: 브라우저 스크립트가 읽을 수 있게 할 응답 헤더
+	ExposedHeaders []string `json:"exposed_headers"`
+	// AllowCredentials: 쿠키/인증 정보를 포함한 요청 허용 (출처 "*"와 함께 쓸 수 없음)
+	AllowCredentials bool `json:"allow_credentials"`
+	// MaxAge: 브라우저가 사전 요청 결과를 캐시하는 시간(초). 0이면 보내지 않음
+	MaxAge int `json:"max_age"`
+}
+
+// LoadPolicy: JSON 파일에서 정책을 읽습니다. 알 수 없는 항목이 있으면 오류입니다. (오타 방지)
+func LoadPolicy(path string) (Policy, error) {
+	var p Policy
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p, fmt.Errorf("read CORS policy: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return p, fmt.Errorf("parse CORS policy %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// originMatcher: 출처 하나와 비교하는 함수
+type originMatcher func(origin string) bool
+
+// CORS: 검증된 정책으로 만든 미들웨어
+type CORS struct {
+	policy      Policy
+	anyOrigin   bool
+	origins     []originMatcher
+	methods     map[string]bool
+	anyHeader   bool
+	headers     map[string]bool
+	allowMethod string // Access-Control-Allow-Methods 값
+	expose      string // Access-Control-Expose-Headers 값
+	maxAge      string
+}
+
+// New: 정책을 검증하고 CORS 미들웨어를 만듭니다.
+func New(p Policy) (*CORS, error) {
+	if len(p.AllowedOrigins) == 0 {
+		return nil, errors.New("CORS policy: allowed_origins is empty")
+	}
+	if p.MaxAge < 0 {
+		return nil, errors.New("CORS policy: max_age must not be negative")
+	}
+	c := &CORS{
+		policy:  p,
+		methods: make(map[string]bool),
+		headers: make(map[string]bool),
+		expose:  strings.Join(p.ExposedHeaders, ", "),
+	}
+
+	for _, o := range p.AllowedOrigins {
+		m, all, err := compileOrigin(o)
+		if err != nil {
+			return nil, err
+		}
+		if all {
+			c.anyOrigin = true
+			continue
+		}
+		c.origins = append(c.origins, m)
+	}
+	if c.anyOrigin && p.AllowCredentials {
+		// 브라우저는 "*" 출처와 자격 증명을 함께 허용하지 않으며, 모든 출처를 되돌려 주는 것은 안전하지 않음
+		return nil, errors.New(`CORS policy: allow_credentials cannot be combined with the "*" origin; list origins explicitly`)
+	}
+
+	allowed := p.AllowedMethods
+	if len(allowed) == 0 {
+		allowed = DefaultMethods
+	}
+	var methods []string
+	for _, m := range allowed {
+		m = strings.ToUpper(strings.TrimSpace(m))
+		methods = append(methods, m)
+		c.methods[m] = true
+	}
+	c.allowMethod = strings.Join(methods, ", ")
+
+	for _, h := range p.AllowedHeaders {
+		h = strings.TrimSpace(h)
+		if h == "*" {
+			c.anyHeader = true
+			continue
+		}
+		c.headers[strings.ToLower(h)] = true
+	}
+	if p.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(p.MaxAge)
+	}
+	return c, nil
+}
+
+// Load: 정책 파일을 읽어 CORS 미들웨어를 만듭니다.
+func Load(path string) (*CORS, error) {
+	p, err := LoadPolicy(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(p)
+}
+
+// compileOrigin: 정책의 출처 항목 하나를 비교 함수로 변환합니다. "*"이면 all이 true입니다.
+func compileOrigin(o string) (m originMatcher, all bool, err error) {
+	o = strings.TrimSpace(o)
+	switch {
+	case o == "*":
+		return nil, true, nil
+	case strings.HasPrefix(o, regexPrefix):
+		// 정규식이 출처 일부에만 맞아도 허용되지 않도록 항상 전체 문자열에 고정 ("app\.example\.com" ≠ "app.example.com.evil.net")
+		re, err := regexp.Compile("^(?:" + strings.TrimPrefix(o, regexPrefix) + ")$")
+		if err != nil {
+			return nil, false, fmt.Errorf("CORS policy: bad origin pattern %q: %w", o, err)
+		}
+		return re.MatchString, false, nil
+	case strings.Count(o, "*") == 1:
+		// "https://*.example.com": *는 비어 있지 않은 하위 도메인 (점 포함 가능)
+		prefix, suffix, _ := strings.Cut(strings.ToLower(o), "*")
+		return func(origin string) bool {
+			origin = strings.ToLower(origin)
+			return len(origin) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) &&
+				!strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:@")
+		}, false, nil
+	case strings.Contains(o, "*"):
+		return nil, false, fmt.Errorf("CORS policy: origin %q may contain at most one '*'", o)
+	default:
+		exact := strings.ToLower(strings.TrimSuffix(o, "/"))
+		return func(origin string) bool { return strings.ToLower(origin) == exact }, false, nil
+	}
+}
+
+// originAllowed: 요청의 Origin 헤더가 정책에 맞는지
+func (c *CORS) originAllowed(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+	for _, m := range c.origins {
+		if m(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// headersAllowed: 사전 요청의 Access-Control-Request-Headers가 모두 허용되는지
+func (c *CORS) headersAllowed(requested string) bool {
+	if c.anyHeader {
+		return true
+	}
+	for _, h := range strings.Split(requested, ",") {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h != "" && !c.headers[h] {
+			return false
+		}
+	}
+	return true
+}
+
+// allowOrigin: Access-Control-Allow-Origin 값 ("*" 정책이면 "*", 아니면 요청한 출처)
+func (c *CORS) allowOrigin(origin string) string {
+	if c.anyOrigin {
+		return "*"
+	}
+	return origin
+}
+
+// Middleware: 허용된 출처의 요청에 CORS 헤더를 붙이고, 사전 요청에는 직접 204로 응답합니다.
+// 허용되지 않은 출처의 요청은 CORS 헤더 없이 처리되므로 브라우저가 응답을 차단합니다.
+func (c *CORS) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if !c.anyOrigin {
+			// 출처에 따라 응답이 달라지므로 캐시가 구분하도록 함
+			h.Add("Vary", "Origin")
+		}
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method, Access-Control-Request-Headers")
+			method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+			requested := r.Header.Get("Access-Control-Request-Headers")
+			if c.originAllowed(origin) && c.methods[method] && c.headersAllowed(requested) {
+				h.Set("Access-Control-Allow-Origin", c.allowOrigin(origin))
+				if c.policy.AllowCredentials {
+					h.Set("Access-Control-Allow-Credentials", "true")
+				}
+				h.Set("Access-Control-Allow-Methods", c.allowMethod)
+				if requested != "" {
+					h.Set("Access-Control-Allow-Headers", requested)
+				}
+				if c.maxAge != "" {
+					h.Set("Access-Control-Max-Age", c.maxAge)
+				}
+			}
+			// 거부한 경우에도 204로 응답하되 CORS 헤더를 빼서 브라우저가 실제 요청을 보내지 않게 함
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if c.originAllowed(origin) {
+			h.Set("Access-Control-Allow-Origin", c.allowOrigin(origin))
+			if c.policy.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			if c.expose != "" {
+				h.Set("Access-Control-Expose-Headers", c.expose)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,244 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{"minimal", Policy{AllowedOrigins: []string{"https://app.example.com"}}, false},
+		{"no origins", Policy{}, true},
+		{"negative max age", Policy{AllowedOrigins: []string{"*"}, MaxAge: -1}, true},
+		{"star with credentials", Policy{AllowedOrigins: []string{"*"}, AllowCredentials: true}, true},
+		{"bad regex", Policy{AllowedOrigins: []string{"regex:https://(app"}}, true},
+		{"two wildcards", Policy{AllowedOrigins: []string{"https://*.*.example.com"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	os.WriteFile(good, []byte(`{"allowed_origins": ["https://app.example.com"], "max_age": 600}`), 0o644)
+	typo := filepath.Join(dir, "typo.json")
+	os.WriteFile(typo, []byte(`{"allowed_origin": ["https://app.example.com"]}`), 0o644)
+
+	p, err := LoadPolicy(good)
+	if err != nil || p.MaxAge != 600 || len(p.AllowedOrigins) != 1 {
+		t.Errorf("LoadPolicy(good) = %+v, %v", p, err)
+	}
+	if _, err := LoadPolicy(typo); err == nil {
+		t.Error("LoadPolicy accepted an unknown key")
+	}
+	if _, err := LoadPolicy(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadPolicy accepted a missing file")
+	}
+}
+
+func TestOriginMatching(t *testing.T) {
+	tests := []struct {
+		pattern string
+		origin  string
+		want    bool
+	}{
+		// 정확한 값 (대소문자, 끝의 '/' 무시)
+		{"https://app.example.com", "https://app.example.com", true},
+		{"https://app.example.com", "HTTPS://APP.EXAMPLE.COM", true},
+		{"https://app.example.com/", "https://app.example.com", true},
+		{"https://app.example.com", "http://app.example.com", false},
+		{"https://app.example.com", "https://app.example.com:8443", false},
+		{"https://app.example.com", "https://app.example.com.evil.net", false},
+
+		// 와일드카드: 하위 도메인 하나 이상, 경로·포트·사용자 정보로 넘어가지 않음
+		{"https://*.example.org", "https://a.example.org", true},
+		{"https://*.example.org", "https://a.b.example.org", true},
+		{"https://*.example.org", "https://example.org", false},
+		{"https://*.example.org", "https://.example.org", false},
+		{"https://*.example.org", "https://evil.net/.example.org", false},
+		{"https://*.example.org", "https://evil.net:1.example.org", false},
+		{"https://*.example.org", "https://a@evil.example.org", false},
+		{"https://*.example.org", "https://a.example.org.evil.net", false},
+
+		// 정규식: 항상 출처 전체와 일치해야 함
+		{`regex:https://app\.example\.com`, "https://app.example.com", true},
+		{`regex:https://app\.example\.com`, "https://app.example.com.evil.net", false},
+		{`regex:https://app\.example\.com`, "evil://https://app.example.com", false},
+		{`regex:^http://localhost:[0-9]+$`, "http://localhost:3000", true},
+		{`regex:^http://localhost:[0-9]+$`, "http://localhost:3000.evil.net", false},
+		{`regex:http://localhost:[0-9]+`, "http://localhost:3000.evil.net", false},
+		{`regex:https://a\.com|https://b\.com`, "https://b.com", true},
+		{`regex:https://a\.com|https://b\.com`, "https://a.com.evil.net", false},
+		{`regex:https://a\.com|https://b\.com`, "https://evil.net/https://b.com", false},
+	}
+	for _, tt := range tests {
+		m, all, err := compileOrigin(tt.pattern)
+		if err != nil || all {
+			t.Fatalf("compileOrigin(%q) = all %v, err %v", tt.pattern, all, err)
+		}
+		if got := m(tt.origin); got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.pattern, tt.origin, got, tt.want)
+		}
+	}
+}
+
+// newTestHandler: 정책으로 만든 미들웨어와 호출 여부를 기록하는 핸들러
+func newTestHandler(t *testing.T, p Policy) (http.Handler, *bool) {
+	t.Helper()
+	c, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	called := new(bool)
+	return c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*called = true
+		w.WriteHeader(http.StatusOK)
+	})), called
+}
+
+func TestPreflight(t *testing.T) {
+	policy := Policy{
+		AllowedOrigins:   []string{"https://app.example.com", `regex:http://localhost:[0-9]+`},
+		AllowedMethods:   []string{"get", "POST", "DELETE"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	}
+	tests := []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+		allowed bool
+	}{
+		{"allowed", "https://app.example.com", "DELETE", "Content-Type, authorization", true},
+		{"allowed regex origin", "http://localhost:3000", "POST", "", true},
+		{"method is case-insensitive", "https://app.example.com", "delete", "", true},
+		{"unknown origin", "https://evil.net", "POST", "", false},
+		{"unanchored regex suffix", "http://localhost:3000.evil.net", "POST", "", false},
+		{"method not allowed", "https://app.example.com", "PUT", "", false},
+		{"header not allowed", "https://app.example.com", "POST", "Content-Type, X-Secret", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, called := newTestHandler(t, policy)
+			r := httptest.NewRequest(http.MethodOptions, "/", nil)
+			r.Header.Set("Origin", tt.origin)
+			r.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				r.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusNoContent || *called {
+				t.Fatalf("status = %d, handler called = %v; want 204 without calling the handler", w.Code, *called)
+			}
+			got := w.Header()
+			if !tt.allowed {
+				for _, k := range []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Methods", "Access-Control-Allow-Credentials"} {
+					if v := got.Get(k); v != "" {
+						t.Errorf("rejected preflight has %s: %q", k, v)
+					}
+				}
+				return
+			}
+			want := map[string]string{
+				"Access-Control-Allow-Origin":      tt.origin,
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "GET, POST, DELETE",
+				"Access-Control-Allow-Headers":     tt.headers,
+				"Access-Control-Max-Age":           "600",
+			}
+			for k, v := range want {
+				if got.Get(k) != v {
+					t.Errorf("%s = %q, want %q", k, got.Get(k), v)
+				}
+			}
+		})
+	}
+}
+
+func TestPreflightAnyOrigin(t *testing.T) {
+	h, _ := newTestHandler(t, Policy{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"*"}})
+	r := httptest.NewRequest(http.MethodOptions, "/", nil)
+	r.Header.Set("Origin", "https://anything.example")
+	r.Header.Set("Access-Control-Request-Method", "GET")
+	r.Header.Set("Access-Control-Request-Headers", "X-Custom")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Headers"); got != "X-Custom" {
+		t.Errorf("Access-Control-Allow-Headers = %q, want X-Custom", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, HEAD, POST" {
+		t.Errorf("Access-Control-Allow-Methods = %q, want the default methods", got)
+	}
+}
+
+func TestSimpleRequest(t *testing.T) {
+	policy := Policy{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		ExposedHeaders:   []string{"X-Request-Id", "ETag"},
+		AllowCredentials: true,
+	}
+	tests := []struct {
+		name       string
+		method     string
+		origin     string
+		wantOrigin string
+	}{
+		{"allowed origin", http.MethodGet, "https://app.example.com", "https://app.example.com"},
+		{"unknown origin still reaches the handler", http.MethodPost, "https://evil.net", ""},
+		{"same-origin request", http.MethodGet, "", ""},
+		{"OPTIONS without Request-Method is not a preflight", http.MethodOptions, "https://app.example.com", "https://app.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, called := newTestHandler(t, policy)
+			r := httptest.NewRequest(tt.method, "/", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if !*called || w.Code != http.StatusOK {
+				t.Fatalf("status = %d, handler called = %v", w.Code, *called)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			wantExpose, wantCreds := "", ""
+			if tt.wantOrigin != "" {
+				wantExpose, wantCreds = "X-Request-Id, ETag", "true"
+			}
+			if got := w.Header().Get("Access-Control-Expose-Headers"); got != wantExpose {
+				t.Errorf("Access-Control-Expose-Headers = %q, want %q", got, wantExpose)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != wantCreds {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, wantCreds)
+			}
+			if got := w.Header().Get("Vary"); got != "Origin" {
+				t.Errorf("Vary = %q, want Origin", got)
+			}
+		})
+	}
+}
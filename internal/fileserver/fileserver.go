@@ -0,0 +1,252 @@
+// Package fileserver는 계산기 서버의 "디렉토리 검색" GET 요청을 처리하는 정적 파일 서버입니다.
+// WriteAuthorizers를 설정하면 PUT(업로드)과 DELETE(삭제)도 처리합니다.
+// 문서 루트 밖으로 나가는 경로('..', 심볼릭 링크)는 os.Root를 통해 원천적으로 차단합니다.
+package fileserver
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry: 디렉토리 목록의 항목 하나
+type Entry struct {
+	Name    string    `json:"name"`
+	IsDir   bool      `json:"is_dir"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// DisplayName: 목록에 표시할 이름 (디렉토리는 '/'로 끝남)
+func (e Entry) DisplayName() string {
+	if e.IsDir {
+		return e.Name + "/"
+	}
+	return e.Name
+}
+
+// Href: 목록에서 항목으로 연결되는 상대 URL
+func (e Entry) Href() string {
+	return (&url.URL{Path: e.DisplayName()}).String()
+}
+
+// ListingRenderer: 디렉토리 목록을 응답으로 출력하는 함수
+// urlPath는 '/'로 끝나는 요청 경로입니다.
+type ListingRenderer func(w http.ResponseWriter, r *http.Request, urlPath string, entries []Entry)
+
+// ErrorHandler: 오류 응답(400/403/404)을 출력하는 함수
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, status int, msg string)
+
+// FileServer: 문서 루트(DocRoot) 아래의 파일과 디렉토리를 제공하는 핸들러
+type FileServer struct {
+	DocRoot       string
+	IndexFile     string          // 디렉토리 요청 시 우선 제공할 파일 (기본값 index.html)
+	RenderListing ListingRenderer // 디렉토리 목록 렌더러 (New에서 지정, HTML은 호출하는 쪽의 템플릿으로 출력)
+	RenderError   ErrorHandler    // nil이면 http.Error 사용
+
+	WriteAuthorizers []Authorizer // PUT/DELETE 권한 검사 훅 (모두 통과해야 허용, 비어 있으면 쓰기 비활성)
+	MaxUploadBytes   int64        // PUT 본문의 최대 크기 (0이면 DefaultMaxUploadBytes)
+
+	root *os.Root
+}
+
+// New: 문서 루트 디렉토리와 디렉토리 목록 렌더러로 FileServer를 생성합니다.
+func New(docRoot string, render ListingRenderer) (*FileServer, error) {
+	if render == nil {
+		return nil, errors.New("fileserver: a listing renderer is required")
+	}
+	root, err := os.OpenRoot(docRoot)
+	if err != nil {
+		return nil, fmt.Errorf("open document root: %w", err)
+	}
+	return &FileServer{
+		DocRoot:       docRoot,
+		IndexFile:     "index.html",
+		RenderListing: render,
+		root:          root,
+	}, nil
+}
+
+// Close: 문서 루트 핸들을 닫습니다.
+func (s *FileServer) Close() error {
+	return s.root.Close()
+}
+
+// ResolvePath: URL 경로를 문서 루트 기준의 상대 경로로 변환합니다.
+// '..' 세그먼트나 NUL 문자가 포함된 경로는 거부합니다.
+func ResolvePath(urlPath string) (string, error) {
+	if strings.ContainsRune(urlPath, 0) || strings.Contains(urlPath, "\\") {
+		return "", errors.New("invalid character in path")
+	}
+	for _, seg := range strings.Split(urlPath, "/") {
+		if seg == ".." {
+			return "", errors.New("path traversal is not allowed")
+		}
+	}
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if name == "" {
+		name = "."
+	}
+	return name, nil
+}
+
+// AllowedMethods: 이 파일 서버가 처리하는 메서드 목록 (Allow 헤더 값)
+func (s *FileServer) AllowedMethods() []string {
+	if s.WritesEnabled() {
+		return []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete}
+	}
+	return []string{http.MethodGet, http.MethodHead}
+}
+
+// ServeHTTP: 파일이면 내용을, 디렉토리이면 index 파일 또는 목록을 응답합니다.
+// Range, If-Modified-Since, If-None-Match(ETag) 처리는 http.ServeContent가 담당합니다.
+// PUT과 DELETE는 WriteAuthorizers를 모두 통과한 경우에만 처리합니다.
+func (s *FileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, err := ResolvePath(r.URL.Path)
+	if err != nil {
+		s.error(w, r, http.StatusBadRequest, "Bad request path: "+err.Error())
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut:
+		s.servePut(w, r, name)
+		return
+	case http.MethodDelete:
+		s.serveDelete(w, r, name)
+		return
+	default:
+		w.Header().Set("Allow", strings.Join(s.AllowedMethods(), ", "))
+		s.error(w, r, http.StatusMethodNotAllowed, "Method not supported")
+		return
+	}
+
+	info, err := s.root.Stat(name)
+	if err != nil {
+		s.serveError(w, r, name, err)
+		return
+	}
+
+	if info.IsDir() {
+		// 디렉토리는 항상 '/'로 끝나는 URL로 제공 (상대 링크가 올바르게 동작하도록)
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			target := r.URL.Path + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
+		}
+
+		if s.IndexFile != "" {
+			index := path.Join(name, s.IndexFile)
+			if indexInfo, err := s.root.Stat(index); err == nil && indexInfo.Mode().IsRegular() {
+				s.serveFile(w, r, index, indexInfo)
+				return
+			}
+		}
+		s.serveDir(w, r, name)
+		return
+	}
+
+	if !info.Mode().IsRegular() {
+		s.error(w, r, http.StatusForbidden, "Forbidden")
+		return
+	}
+	s.serveFile(w, r, name, info)
+}
+
+// serveFile: 일반 파일을 전송합니다.
+func (s *FileServer) serveFile(w http.ResponseWriter, r *http.Request, name string, info fs.FileInfo) {
+	f, err := s.root.Open(name)
+	if err != nil {
+		s.serveError(w, r, name, err)
+		return
+	}
+	defer f.Close()
+
+	// 크기와 수정 시각으로 만든 ETag (파일이 바뀌면 값도 바뀝니다)
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+	// MIME 타입은 확장자, 없으면 내용 스니핑으로 ServeContent가 결정합니다.
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// serveDir: 디렉토리 목록을 읽어 렌더러에 전달합니다.
+func (s *FileServer) serveDir(w http.ResponseWriter, r *http.Request, name string) {
+	entries, err := s.ReadDir(name)
+	if err != nil {
+		s.serveError(w, r, name, err)
+		return
+	}
+
+	if s.RenderListing == nil {
+		s.error(w, r, http.StatusForbidden, "Directory listing is disabled")
+		return
+	}
+	s.RenderListing(w, r, r.URL.Path, entries)
+}
+
+// ReadDir: 문서 루트 기준 디렉토리의 항목을 이름순(디렉토리 먼저)으로 반환합니다.
+func (s *FileServer) ReadDir(name string) ([]Entry, error) {
+	dir, err := s.root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	dirEntries, err := dir.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		info, err := de.Info()
+		if err != nil {
+			continue // 목록을 읽는 사이 삭제된 파일
+		}
+		entries = append(entries, Entry{
+			Name:    de.Name(),
+			IsDir:   de.IsDir(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// serveError: 파일 시스템 오류를 HTTP 상태 코드로 변환합니다.
+// 루트 밖을 가리키는 심볼릭 링크 등 os.Root가 거부한 접근은 403으로 응답합니다.
+func (s *FileServer) serveError(w http.ResponseWriter, r *http.Request, name string, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		s.error(w, r, http.StatusNotFound, "404 page not found")
+	default:
+		log.Printf("fileserver: access to %q denied: %v", name, err)
+		s.error(w, r, http.StatusForbidden, "Forbidden")
+	}
+}
+
+// error: RenderError가 설정되어 있으면 사용하고, 없으면 http.Error로 응답합니다.
+func (s *FileServer) error(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	if s.RenderError != nil {
+		s.RenderError(w, r, status, msg)
+		return
+	}
+	http.Error(w, msg, status)
+}
@@ -0,0 +1,309 @@
+package fileserver
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"full_stack_service_networking_project/internal/identity"
+)
+
+// DefaultMaxUploadBytes: MaxUploadBytes가 0일 때 PUT 본문의 최대 크기
+const DefaultMaxUploadBytes = 10 << 20
+
+// uploadSeq: 업로드 임시 파일 이름을 구분하는 일련번호
+var uploadSeq atomic.Uint64
+
+// 권한 검사 실패 이유. Authorizer가 이 오류(또는 이를 감싼 오류)를 반환하면 해당 상태 코드로 응답합니다.
+var (
+	ErrUnauthorized = errors.New("authentication required") // 401
+	ErrForbidden    = errors.New("forbidden")               // 403
+)
+
+// Authorizer: PUT/DELETE 요청이 문서 루트 기준 경로 name을 수정해도 되는지 검사하는 훅.
+// name은 상위 디렉토리의 심볼릭 링크를 따라간 실제 경로입니다. (realName 참고)
+// 허용하면 nil, 거부하면 ErrUnauthorized 또는 ErrForbidden을 감싼 오류를 반환합니다.
+type Authorizer func(r *http.Request, name string) error
+
+// UnderDirs: 지정한 디렉토리(문서 루트 기준) 아래의 파일만 수정할 수 있게 합니다. 디렉토리 자체는 수정할 수 없습니다.
+// 요청 경로는 심볼릭 링크를 따라간 뒤 비교하므로 dirs도 링크가 아닌 실제 디렉토리로 지정해야 합니다.
+func UnderDirs(dirs ...string) Authorizer {
+	var prefixes []string
+	for _, d := range dirs {
+		name, err := ResolvePath(d)
+		if err != nil || name == "." {
+			continue // 문서 루트 전체를 쓰기 가능하게 하는 설정은 무시
+		}
+		prefixes = append(prefixes, name+"/")
+	}
+	return func(r *http.Request, name string) error {
+		for _, p := range prefixes {
+			if strings.HasPrefix(name, p) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: writes are only allowed under %s", ErrForbidden, strings.Join(prefixes, ", "))
+	}
+}
+
+// BearerToken: "Authorization: Bearer <token>" 헤더가 token과 일치해야 합니다.
+func BearerToken(token string) Authorizer {
+	return func(r *http.Request, name string) error {
+		scheme, got, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return fmt.Errorf("%w: missing bearer token", ErrUnauthorized)
+		}
+		if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(got)), []byte(token)) != 1 {
+			return fmt.Errorf("%w: invalid bearer token", ErrUnauthorized)
+		}
+		return nil
+	}
+}
+
+// ClientNames: mTLS 클라이언트 인증서의 이름(identity.Identity.Name)이 names 중 하나여야 합니다.
+func ClientNames(names ...string) Authorizer {
+	allowed := make(map[string]bool, len(names))
+	for _, n := range names {
+		allowed[n] = true
+	}
+	return func(r *http.Request, name string) error {
+		id := identity.FromContext(r.Context())
+		if id == nil {
+			id = identity.FromRequest(r)
+		}
+		if id == nil {
+			return fmt.Errorf("%w: client certificate required", ErrUnauthorized)
+		}
+		if !allowed[id.Name] {
+			return fmt.Errorf("%w: client %q may not modify files", ErrForbidden, id.Name)
+		}
+		return nil
+	}
+}
+
+// WritesEnabled: PUT/DELETE를 받는지 여부 (WriteAuthorizers가 하나 이상 설정됨)
+func (s *FileServer) WritesEnabled() bool {
+	return len(s.WriteAuthorizers) > 0
+}
+
+// authorize: 모든 WriteAuthorizers를 통과해야 수정을 허용합니다.
+func (s *FileServer) authorize(w http.ResponseWriter, r *http.Request, name string) bool {
+	if !s.WritesEnabled() {
+		s.error(w, r, http.StatusForbidden, "Writes are disabled on this server")
+		return false
+	}
+	// 쓰기 허용 디렉토리 안의 링크로 다른 디렉토리의 파일을 고치지 못하도록 실제 경로로 검사
+	resolved, err := s.realName(name)
+	if err != nil {
+		log.Printf("fileserver: %s %q denied: %v", r.Method, name, err)
+		s.error(w, r, http.StatusForbidden, "Forbidden")
+		return false
+	}
+	for _, auth := range s.WriteAuthorizers {
+		err := auth(r, resolved)
+		switch {
+		case err == nil:
+			continue
+		case errors.Is(err, ErrUnauthorized):
+			w.Header().Set("WWW-Authenticate", `Bearer realm="fileserver"`)
+			s.error(w, r, http.StatusUnauthorized, err.Error())
+		default:
+			s.error(w, r, http.StatusForbidden, err.Error())
+		}
+		log.Printf("fileserver: %s %q denied: %v", r.Method, name, err)
+		return false
+	}
+	return true
+}
+
+// realName: name의 상위 디렉토리에 있는 심볼릭 링크를 따라간 문서 루트 기준 실제 경로를 반환합니다.
+// 마지막 요소는 따라가지 않습니다. (PUT은 링크 자리에 새 파일을 만들고 DELETE는 링크 자체를 지움)
+// 아직 없는 디렉토리는 PUT이 새로 만들므로 존재하는 가장 가까운 상위 디렉토리까지만 해석하며, 대상이 없는 링크는 거부합니다.
+func (s *FileServer) realName(name string) (string, error) {
+	base, err := filepath.EvalSymlinks(s.DocRoot)
+	if err != nil {
+		return "", err
+	}
+	dir, rest := path.Dir(name), path.Base(name)
+	for {
+		p := filepath.Join(base, filepath.FromSlash(dir))
+		target, err := filepath.EvalSymlinks(p)
+		if err == nil {
+			rel, err := filepath.Rel(base, target)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return "", fmt.Errorf("%s resolves outside the document root", dir)
+			}
+			return path.Join(filepath.ToSlash(rel), rest), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) || dir == "." {
+			return "", err
+		}
+		if _, err := os.Lstat(p); err == nil {
+			return "", fmt.Errorf("%s is a dangling symbolic link", dir)
+		}
+		dir, rest = path.Dir(dir), path.Join(path.Base(dir), rest)
+	}
+}
+
+// etag: 크기와 수정 시각으로 만든 ETag (serveFile과 같은 형식)
+func etag(info fs.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+}
+
+// checkPreconditions: If-Match / If-None-Match: * 조건을 검사합니다. (RFC 9110 13.1)
+// 동기화 스크립트가 다른 클라이언트의 변경을 덮어쓰지 않도록 사용합니다.
+func checkPreconditions(r *http.Request, info fs.FileInfo) bool {
+	if im := r.Header.Get("If-Match"); im != "" {
+		if info == nil {
+			return false
+		}
+		if strings.TrimSpace(im) != "*" && !containsETag(im, etag(info)) {
+			return false
+		}
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" && info != nil {
+		if strings.TrimSpace(inm) == "*" || containsETag(inm, etag(info)) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsETag: 쉼표로 구분된 ETag 목록에 tag가 있는지 (약한 비교)
+func containsETag(list, tag string) bool {
+	for _, t := range strings.Split(list, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(t), "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// servePut: 요청 본문으로 파일을 만들거나 교체합니다.
+// 같은 디렉토리의 임시 파일에 쓴 뒤 이름을 바꾸므로, 읽는 쪽은 이전 내용이나 새 내용만 보게 됩니다.
+func (s *FileServer) servePut(w http.ResponseWriter, r *http.Request, name string) {
+	if strings.HasSuffix(r.URL.Path, "/") || name == "." {
+		s.error(w, r, http.StatusMethodNotAllowed, "PUT target must be a file, not a directory")
+		return
+	}
+	if !s.authorize(w, r, name) {
+		return
+	}
+
+	info, err := s.root.Stat(name)
+	switch {
+	case err == nil && !info.Mode().IsRegular():
+		s.error(w, r, http.StatusConflict, "Target exists and is not a regular file")
+		return
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		s.serveError(w, r, name, err)
+		return
+	case err != nil:
+		info = nil
+	}
+	if !checkPreconditions(r, info) {
+		s.error(w, r, http.StatusPreconditionFailed, "Precondition failed")
+		return
+	}
+
+	dir := path.Dir(name)
+	if err := s.root.MkdirAll(dir, 0o755); err != nil {
+		s.writeError(w, r, name, err)
+		return
+	}
+	tmpName := path.Join(dir, fmt.Sprintf(".%s.upload-%d", path.Base(name), uploadSeq.Add(1)))
+	tmp, err := s.root.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		s.writeError(w, r, name, err)
+		return
+	}
+
+	limit := s.MaxUploadBytes
+	if limit <= 0 {
+		limit = DefaultMaxUploadBytes
+	}
+	_, copyErr := io.Copy(tmp, http.MaxBytesReader(w, r.Body, limit))
+	closeErr := tmp.Close()
+	if copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr == nil {
+		copyErr = s.root.Rename(tmpName, name)
+	}
+	if copyErr != nil {
+		s.root.Remove(tmpName)
+		var maxErr *http.MaxBytesError
+		if errors.As(copyErr, &maxErr) {
+			s.error(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("Upload exceeds %d bytes", maxErr.Limit))
+			return
+		}
+		s.writeError(w, r, name, copyErr)
+		return
+	}
+
+	if newInfo, err := s.root.Stat(name); err == nil {
+		w.Header().Set("ETag", etag(newInfo))
+	}
+	log.Printf("fileserver: PUT %q (%s)", name, r.RemoteAddr)
+	if info == nil {
+		w.Header().Set("Location", r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveDelete: 파일 또는 빈 디렉토리를 삭제합니다.
+func (s *FileServer) serveDelete(w http.ResponseWriter, r *http.Request, name string) {
+	if name == "." {
+		s.error(w, r, http.StatusMethodNotAllowed, "Cannot delete the document root")
+		return
+	}
+	if !s.authorize(w, r, name) {
+		return
+	}
+
+	info, err := s.root.Lstat(name)
+	if err != nil {
+		s.serveError(w, r, name, err)
+		return
+	}
+	if !checkPreconditions(r, info) {
+		s.error(w, r, http.StatusPreconditionFailed, "Precondition failed")
+		return
+	}
+	if err := s.root.Remove(name); err != nil {
+		if errors.Is(err, syscall.ENOTEMPTY) || errors.Is(err, syscall.EEXIST) {
+			s.error(w, r, http.StatusConflict, "Directory is not empty")
+			return
+		}
+		s.writeError(w, r, name, err)
+		return
+	}
+	log.Printf("fileserver: DELETE %q (%s)", name, r.RemoteAddr)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError: 쓰기 중의 파일 시스템 오류를 상태 코드로 변환합니다.
+func (s *FileServer) writeError(w http.ResponseWriter, r *http.Request, name string, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, syscall.ENOTDIR):
+		s.error(w, r, http.StatusConflict, "Parent path is not a directory")
+	case errors.Is(err, fs.ErrPermission):
+		log.Printf("fileserver: write to %q denied: %v", name, err)
+		s.error(w, r, http.StatusForbidden, "Forbidden")
+	default:
+		log.Printf("fileserver: write to %q failed: %v", name, err)
+		s.error(w, r, http.StatusInternalServerError, "Write failed")
+	}
+}
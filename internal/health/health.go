@@ -0,0 +1,110 @@
+// Package health는 오케스트레이터(쿠버네티스, 로드 밸런서)가 사용하는 생존(liveness)·준비(readiness) 확인 핸들러를 제공합니다.
+//
+//   - /healthz: 프로세스가 요청에 응답할 수 있으면 항상 200
+//   - /readyz: 등록된 확인(저장소 등)이 모두 통과하고 종료 중이 아니면 200, 아니면 503
+//
+// 종료가 시작되면 SetDraining으로 준비 상태를 내려, 로드 밸런서가 새 요청을 다른 인스턴스로 보내게 합니다.
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CheckTimeout: 준비 확인 전체에 허용하는 시간
+const CheckTimeout = 2 * time.Second
+
+// Check: 의존하는 자원 하나의 상태를 확인합니다. 사용할 수 없으면 오류를 반환합니다.
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker: 준비 확인 목록과 종료 상태
+type Checker struct {
+	mu       sync.Mutex
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+// New: 확인 항목이 없는 Checker를 만듭니다.
+func New() *Checker {
+	return &Checker{}
+}
+
+// Add: 준비 확인 항목을 등록합니다. (예: "history" → 기록 파일 쓰기 오류 확인)
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// SetDraining: 종료가 시작되었음을 표시합니다. 이후 /readyz는 503을 반환합니다.
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Draining: 종료 중인지
+func (c *Checker) Draining() bool {
+	return c.draining.Load()
+}
+
+// Ready: 준비 상태를 확인합니다. 항목별 결과 줄("ok history", "fail history: ...")과 통과 여부를 반환합니다.
+func (c *Checker) Ready(ctx context.Context) (lines []string, ok bool) {
+	ok = true
+	if c.Draining() {
+		lines = append(lines, "fail shutdown: server is shutting down")
+		ok = false
+	}
+
+	c.mu.Lock()
+	checks := append([]namedCheck(nil), c.checks...)
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+	for _, nc := range checks {
+		if err := nc.check(ctx); err != nil {
+			lines = append(lines, fmt.Sprintf("fail %s: %v", nc.name, err))
+			ok = false
+			continue
+		}
+		lines = append(lines, "ok "+nc.name)
+	}
+	return lines, ok
+}
+
+// writeStatus: 확인 결과를 텍스트로 응답합니다. (캐시 금지)
+func writeStatus(w http.ResponseWriter, status int, body string) {
+	h := w.Header()
+	h.Set("Content-Type", "text/plain; charset=utf-8")
+	h.Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	fmt.Fprintln(w, body)
+}
+
+// LivenessHandler: /healthz 핸들러. 프로세스가 살아 있으면 "ok"를 반환합니다.
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeStatus(w, http.StatusOK, "ok")
+	})
+}
+
+// ReadinessHandler: /readyz 핸들러. 모두 통과하면 200, 아니면 503과 실패 항목을 반환합니다.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lines, ok := c.Ready(r.Context())
+		if !ok {
+			writeStatus(w, http.StatusServiceUnavailable, strings.Join(append(lines, "not ready"), "\n"))
+			return
+		}
+		writeStatus(w, http.StatusOK, strings.Join(append(lines, "ready"), "\n"))
+	})
+}
@@ -0,0 +1,305 @@
+// Package history는 계산 기록을 크기가 제한된 메모리 버퍼에 보관하고,
+// 선택적으로 JSON Lines 파일에 기록하여 재시작 후에도 유지합니다.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Record: 계산 기록 하나
+type Record struct {
+	ID     uint64    `json:"id"`
+	Time   time.Time `json:"time"`
+	Client string    `json:"client"`
+	Method string    `json:"method"`
+	Op     string    `json:"op"`
+	Var1   any       `json:"var1,omitempty"`
+	Var2   any       `json:"var2,omitempty"`
+	Expr   string    `json:"expr,omitempty"`
+	Mode   string    `json:"mode,omitempty"`
+	Result any       `json:"result,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Store: 최근 Capacity개의 기록을 보관하는 동시성 안전한 저장소
+// Path가 있으면 기록을 파일에 추가하고, 파일이 Capacity의 두 배를 넘으면 최근 기록만 남기고 다시 씁니다.
+type Store struct {
+	Capacity int
+	Path     string
+
+	mu      sync.Mutex
+	records []Record // 링 버퍼
+	start   int      // 가장 오래된 기록의 위치
+	nextID  uint64
+	file    *os.File
+	lines   int   // 파일에 기록된 줄 수
+	lastErr error // 마지막 파일 기록 결과 (Err)
+}
+
+// New: 메모리에만 보관하는 저장소를 만듭니다.
+func New(capacity int) *Store {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Store{Capacity: capacity, records: make([]Record, 0, capacity), nextID: 1}
+}
+
+// Open: path 파일의 기존 기록을 읽어 들이고, 이후 기록을 그 파일에 추가하는 저장소를 만듭니다.
+// path가 비어 있으면 New와 같습니다.
+func Open(path string, capacity int) (*Store, error) {
+	s := New(capacity)
+	if path == "" {
+		return s, nil
+	}
+	s.Path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read history file: %w", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue // 중단된 쓰기 등으로 손상된 줄은 건너뜀
+		}
+		s.push(rec)
+		s.nextID = max(s.nextID, rec.ID+1)
+		s.lines++
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create history directory: %w", err)
+	}
+	// 이전 파일이 크면 보관 중인 기록만 남기고 정리
+	if s.lines > s.Capacity {
+		if err := s.compact(); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	s.file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open history file: %w", err)
+	}
+	return s, nil
+}
+
+// Add: 기록을 추가하고 ID와 시각(비어 있으면)을 채워 반환합니다.
+// 파일 기록 오류는 반환하지만, 메모리에는 항상 추가됩니다.
+func (s *Store) Add(rec Record) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec.ID = s.nextID
+	s.nextID++
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	s.push(rec)
+
+	if s.file == nil {
+		return rec, nil
+	}
+	s.lastErr = s.write(rec)
+	return rec, s.lastErr
+}
+
+// write: 기록 한 줄을 파일에 추가하고, 필요하면 파일을 정리합니다. (잠금 상태에서 호출)
+func (s *Store) write(rec Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode history record: %w", err)
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write history file: %w", err)
+	}
+	s.lines++
+	if s.lines >= 2*s.Capacity {
+		return s.compact()
+	}
+	return nil
+}
+
+// Err: 마지막 파일 기록이 실패했으면 그 오류를 반환합니다. (디스크 가득 참 등 저장소 장애 확인용)
+// 정리(compact) 중 실패하면 파일이 닫혀 이후 기록이 저장되지 않으므로, 오류가 계속 유지됩니다.
+func (s *Store) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastErr
+}
+
+// push: 링 버퍼에 추가합니다. 가득 차면 가장 오래된 기록을 덮어씁니다. (잠금 상태에서 호출)
+func (s *Store) push(rec Record) {
+	if len(s.records) < s.Capacity {
+		s.records = append(s.records, rec)
+		return
+	}
+	s.records[s.start] = rec
+	s.start = (s.start + 1) % s.Capacity
+}
+
+// snapshot: 오래된 순서의 기록 복사본 (잠금 상태에서 호출)
+func (s *Store) snapshot() []Record {
+	out := make([]Record, 0, len(s.records))
+	out = append(out, s.records[s.start:]...)
+	return append(out, s.records[:s.start]...)
+}
+
+// compact: 보관 중인 기록만으로 파일을 다시 씁니다. (잠금 상태에서 호출)
+func (s *Store) compact() error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	recs := s.snapshot()
+	for _, rec := range recs {
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("encode history record: %w", err)
+		}
+	}
+
+	// 임시 파일에 쓴 뒤 교체하여, 중간에 중단되어도 기존 파일이 남도록 함
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write history file: %w", err)
+	}
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	if err := os.Rename(tmp, s.Path); err != nil {
+		return fmt.Errorf("replace history file: %w", err)
+	}
+	f, err := os.OpenFile(s.Path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open history file: %w", err)
+	}
+	s.file, s.lines = f, len(recs)
+	return nil
+}
+
+// Close: 파일을 디스크에 기록하고 닫습니다.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Sync()
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+	s.file = nil
+	return err
+}
+
+// Query: 기록 조회 조건
+type Query struct {
+	Since  time.Time // 이 시각 이후 (0이면 제한 없음)
+	Until  time.Time // 이 시각 이전 (0이면 제한 없음)
+	Client string    // 클라이언트 IP (비어 있으면 전체)
+	Offset int
+	Limit  int // 0이면 전체
+}
+
+// Page: 조회 결과 (최신 기록이 먼저 옴)
+type Page struct {
+	Total   int      `json:"total"` // 조건에 맞는 전체 기록 수
+	Offset  int      `json:"offset"`
+	Limit   int      `json:"limit"`
+	Records []Record `json:"records"`
+}
+
+// Find: 조건에 맞는 기록을 최신 순으로 조회합니다.
+func (s *Store) Find(q Query) Page {
+	s.mu.Lock()
+	recs := s.snapshot()
+	s.mu.Unlock()
+
+	matched := make([]Record, 0, len(recs))
+	for i := len(recs) - 1; i >= 0; i-- {
+		rec := recs[i]
+		if !q.Since.IsZero() && rec.Time.Before(q.Since) {
+			continue
+		}
+		if !q.Until.IsZero() && rec.Time.After(q.Until) {
+			continue
+		}
+		if q.Client != "" && rec.Client != q.Client {
+			continue
+		}
+		matched = append(matched, rec)
+	}
+
+	page := Page{Total: len(matched), Offset: q.Offset, Limit: q.Limit}
+	if q.Offset >= len(matched) {
+		page.Records = []Record{}
+		return page
+	}
+	matched = matched[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(matched) {
+		matched = matched[:q.Limit]
+	}
+	page.Records = matched
+	return page
+}
+
+// ClientCount: 클라이언트별 요청 수
+type ClientCount struct {
+	Client string `json:"client"`
+	Count  int    `json:"count"`
+}
+
+// Stats: 보관 중인 기록의 통계
+type Stats struct {
+	Total      int            `json:"total"`
+	Errors     int            `json:"errors"`
+	ErrorRate  float64        `json:"error_rate"`
+	ByOp       map[string]int `json:"by_op"`
+	TopClients []ClientCount  `json:"top_clients"`
+	Since      time.Time      `json:"since,omitzero"` // 가장 오래된 기록의 시각
+}
+
+// Stats: 연산별 횟수, 오류율, 요청이 많은 클라이언트 상위 top개를 계산합니다.
+func (s *Store) Stats(top int) Stats {
+	s.mu.Lock()
+	recs := s.snapshot()
+	s.mu.Unlock()
+
+	st := Stats{Total: len(recs), ByOp: map[string]int{}, TopClients: []ClientCount{}}
+	clients := map[string]int{}
+	for _, rec := range recs {
+		st.ByOp[rec.Op]++
+		clients[rec.Client]++
+		if rec.Error != "" {
+			st.Errors++
+		}
+	}
+	if st.Total > 0 {
+		st.ErrorRate = float64(st.Errors) / float64(st.Total)
+		st.Since = recs[0].Time
+	}
+
+	for client, n := range clients {
+		st.TopClients = append(st.TopClients, ClientCount{client, n})
+	}
+	sort.Slice(st.TopClients, func(i, j int) bool {
+		a, b := st.TopClients[i], st.TopClients[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Client < b.Client
+	})
+	if top > 0 && len(st.TopClients) > top {
+		st.TopClients = st.TopClients[:top]
+	}
+	return st
+}
@@ -0,0 +1,76 @@
+package httpclient
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// acceptEncoding: 클라이언트가 보내는 Accept-Encoding (gzip 우선)
+const acceptEncoding = "gzip, deflate;q=0.9"
+
+// decodingTransport: Accept-Encoding을 보내고 gzip/deflate로 압축된 응답 본문을 풀어 주는 RoundTripper.
+// http.Transport의 기본 압축 처리는 gzip만 지원하므로 직접 처리합니다.
+type decodingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *decodingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// 호출자가 Accept-Encoding을 직접 지정했거나 Range 요청이면 압축된 바이트를 그대로 돌려줌
+	if req.Header.Get("Accept-Encoding") != "" || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context()) // RoundTripper는 받은 요청을 수정하면 안 됨
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var open func(io.Reader) (io.ReadCloser, error)
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		open = func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }
+	case "deflate":
+		open = zlib.NewReader
+	default:
+		return resp, nil
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	if req.Method != http.MethodHead && resp.Body != http.NoBody {
+		resp.Body = &decodedBody{body: resp.Body, open: open}
+	}
+	return resp, nil
+}
+
+// decodedBody: 처음 읽을 때 압축 해제기를 만드는 응답 본문
+// (스트리밍 응답에서 헤더를 받자마자 본문을 기다리며 멈추지 않도록 지연 생성)
+type decodedBody struct {
+	body io.ReadCloser
+	open func(io.Reader) (io.ReadCloser, error)
+	r    io.ReadCloser
+	err  error
+}
+
+func (b *decodedBody) Read(p []byte) (int, error) {
+	if b.r == nil && b.err == nil {
+		b.r, b.err = b.open(b.body)
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.r.Read(p)
+}
+
+func (b *decodedBody) Close() error {
+	if b.r != nil {
+		b.r.Close()
+	}
+	return b.body.Close()
+}
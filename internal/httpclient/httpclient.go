@@ -0,0 +1,113 @@
+// Package httpclient는 예제 클라이언트 프로그램(lec-06-prg-01, lec-06-prg-08)이 공통으로 사용하는
+// http.Client 구성(신뢰할 CA, 압축 응답 해제 등)을 제공합니다.
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"full_stack_service_networking_project/internal/tlsutil"
+)
+
+// Options: 클라이언트 설정
+type Options struct {
+	CAFile   string // 시스템 신뢰 저장소에 추가로 신뢰할 PEM CA 묶음 (HTTPS 개발 모드의 certs/ca.pem 등)
+	CertFile string // mTLS 서버에 제시할 클라이언트 인증서 (PEM)
+	KeyFile  string // 클라이언트 인증서의 개인키 (PEM)
+
+	// UnixSocket: 설정하면 URL의 호스트와 관계없이 이 Unix 소켓으로 연결합니다. (ParseTarget이 unix:// 주소에서 채움)
+	UnixSocket string
+}
+
+// Validate: 함께 지정해야 하는 설정을 확인합니다.
+func (o Options) Validate() error {
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return errors.New("client certificate and key must be given together")
+	}
+	if o.UnixSocket != "" && (o.CertFile != "" || o.CAFile != "") {
+		return errors.New("TLS options cannot be used with a unix:// address (Unix sockets carry plain HTTP)")
+	}
+	return nil
+}
+
+// Target: 요청을 보낼 기준 URL과 연결할 Unix 소켓
+type Target struct {
+	BaseURL    string // http:// 또는 https:// 기준 URL (unix:// 주소는 http://localhost에 HTTP 경로를 붙인 URL)
+	UnixSocket string // unix:// 주소의 소켓 경로 (TCP이면 "")
+}
+
+// ParseTarget: name 설정(플래그 이름)의 값을 해석합니다.
+// http://, https:// 절대 URL 또는 unix://<소켓 경로>[:<HTTP 경로>] 형식을 받습니다.
+//
+//	unix:///run/calc.sock                      → /run/calc.sock의 http://localhost
+//	unix:///run/membership.sock:/membership_api/ → /run/membership.sock의 http://localhost/membership_api/
+func ParseTarget(name, raw string) (Target, error) {
+	if rest, ok := strings.CutPrefix(raw, "unix://"); ok {
+		socket, path, _ := strings.Cut(rest, ":")
+		if socket == "" {
+			return Target{}, fmt.Errorf("-%s %q: missing socket path (want unix:///path/to.sock[:/http/path])", name, raw)
+		}
+		if path != "" && !strings.HasPrefix(path, "/") {
+			return Target{}, fmt.Errorf("-%s %q: HTTP path after the socket must start with /", name, raw)
+		}
+		return Target{BaseURL: "http://localhost" + path, UnixSocket: socket}, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Target{}, fmt.Errorf("-%s %q: want an http://, https:// or unix:// URL", name, raw)
+	}
+	return Target{BaseURL: raw}, nil
+}
+
+// DialContext: opts에 맞게 addr(host:port)로 연결합니다. UnixSocket이 있으면 addr 대신 소켓으로 연결합니다.
+// (http.Client를 쓰지 않는 WebSocket 연결 등에 사용)
+func DialContext(ctx context.Context, opts Options, addr string) (net.Conn, error) {
+	var d net.Dialer
+	if opts.UnixSocket != "" {
+		return d.DialContext(ctx, "unix", opts.UnixSocket)
+	}
+	return d.DialContext(ctx, "tcp", addr)
+}
+
+// TLSConfig: 설정을 반영한 클라이언트 TLS 설정을 생성합니다. (http.Client를 쓰지 않는 WebSocket 연결 등에 사용)
+func TLSConfig(opts Options) (*tls.Config, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := tlsutil.ClientConfig(opts.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		pair, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+	return tlsConfig, nil
+}
+
+// New: 설정을 반영한 http.Client를 생성합니다.
+func New(opts Options) (*http.Client, error) {
+	tlsConfig, err := TLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.DisableCompression = true // gzip과 deflate를 모두 decodingTransport가 처리
+	if opts.UnixSocket != "" {
+		transport.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return DialContext(ctx, opts, addr)
+		}
+		transport.Proxy = nil // 로컬 소켓 요청은 HTTP_PROXY를 거치지 않음
+	}
+	return &http.Client{Transport: &decodingTransport{base: transport}}, nil
+}
@@ -0,0 +1,78 @@
+// Package identity는 mTLS 클라이언트 인증서로부터 호출자 신원(identity)을 추출하여
+// 요청 컨텍스트로 핸들러에 전달합니다.
+package identity
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+)
+
+// Identity: 검증된 클라이언트 인증서에서 얻은 호출자 정보
+type Identity struct {
+	Name    string // 로그와 핸들러에서 사용하는 호출자 이름 (SAN 또는 CN)
+	Subject string // 인증서 Subject 전체 (예: CN=alice,O=...)
+	Serial  string // 인증서 일련번호 (16진수)
+	Issuer  string // 발급 CA의 Subject
+}
+
+// FromCertificate: 인증서를 Identity로 변환합니다.
+// 이름은 이메일 SAN, URI SAN, DNS SAN, Subject CN 순서로 처음 발견된 값을 사용합니다.
+func FromCertificate(cert *x509.Certificate) *Identity {
+	id := &Identity{
+		Subject: cert.Subject.String(),
+		Serial:  cert.SerialNumber.Text(16),
+		Issuer:  cert.Issuer.String(),
+	}
+	switch {
+	case len(cert.EmailAddresses) > 0:
+		id.Name = cert.EmailAddresses[0]
+	case len(cert.URIs) > 0:
+		id.Name = cert.URIs[0].String()
+	case len(cert.DNSNames) > 0:
+		id.Name = cert.DNSNames[0]
+	default:
+		id.Name = cert.Subject.CommonName
+	}
+	return id
+}
+
+// FromRequest: TLS 핸드셰이크에서 검증된 클라이언트 인증서의 Identity를 반환합니다.
+// 평문 요청이거나 검증된 인증서가 없으면 nil을 반환합니다.
+func FromRequest(r *http.Request) *Identity {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return FromCertificate(r.TLS.VerifiedChains[0][0])
+}
+
+type ctxKey struct{}
+
+// NewContext: Identity를 저장한 컨텍스트를 반환합니다.
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext: 컨텍스트에 저장된 Identity (없으면 nil)
+func FromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(ctxKey{}).(*Identity)
+	return id
+}
+
+// Name: 컨텍스트의 호출자 이름 (없으면 "anonymous")
+func Name(ctx context.Context) string {
+	if id := FromContext(ctx); id != nil && id.Name != "" {
+		return id.Name
+	}
+	return "anonymous"
+}
+
+// Middleware: 클라이언트 인증서의 Identity를 요청 컨텍스트에 저장합니다.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := FromRequest(r); id != nil {
+			r = r.WithContext(NewContext(r.Context(), id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,229 @@
+// Package listener는 서버의 -addr 값(쉼표로 구분한 주소 목록)으로 리스너를 엽니다.
+//
+//	:8080, 127.0.0.1:8080, [::1]:8080   TCP
+//	unix:/run/calc.sock                 Unix 도메인 소켓 (파일 권한과 소유자 지정 가능)
+//	systemd, systemd:<이름>              systemd 소켓 활성화로 전달받은 소켓 (LISTEN_FDS, 이름은 LISTEN_FDNAMES)
+//
+// 예: -addr ":8080,unix:/run/calc.sock"는 TCP 포트와 Unix 소켓에서 함께 요청을 받습니다.
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 주소 종류 (Addr.Network)
+const (
+	NetworkTCP     = "tcp"
+	NetworkUnix    = "unix"
+	NetworkSystemd = "systemd"
+)
+
+// DefaultSocketMode: Unix 소켓 파일의 기본 권한 (소유자와 그룹만 연결 가능)
+const DefaultSocketMode fs.FileMode = 0o660
+
+// Addr: 리스너 주소 하나
+type Addr struct {
+	Network string // NetworkTCP, NetworkUnix 또는 NetworkSystemd
+	Address string // TCP는 host:port, Unix는 소켓 파일 경로, systemd는 소켓 이름 (비어 있으면 전달받은 소켓 전체)
+}
+
+// String: -addr에 쓰는 형식 (":8080", "unix:/run/calc.sock", "systemd:web")
+func (a Addr) String() string {
+	switch a.Network {
+	case NetworkTCP:
+		return a.Address
+	case NetworkSystemd:
+		if a.Address == "" {
+			return NetworkSystemd
+		}
+	}
+	return a.Network + ":" + a.Address
+}
+
+// Parse: 쉼표로 구분한 주소 목록을 해석합니다.
+func Parse(list string) ([]Addr, error) {
+	var addrs []Addr
+	seen := make(map[Addr]bool)
+	for _, s := range strings.Split(list, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		var a Addr
+		switch {
+		case s == NetworkSystemd:
+			a = Addr{Network: NetworkSystemd}
+		case strings.HasPrefix(s, "systemd:"):
+			a = Addr{Network: NetworkSystemd, Address: strings.TrimPrefix(s, "systemd:")}
+		case strings.HasPrefix(s, "unix:"):
+			path := strings.TrimPrefix(strings.TrimPrefix(s, "unix:"), "//") // unix:///run/x.sock도 허용
+			if path == "" {
+				return nil, fmt.Errorf("listen address %q: missing socket path", s)
+			}
+			a = Addr{Network: NetworkUnix, Address: path}
+		default:
+			if _, _, err := net.SplitHostPort(s); err != nil {
+				return nil, fmt.Errorf("listen address %q: want host:port, unix:<path> or systemd[:<name>]", s)
+			}
+			a = Addr{Network: NetworkTCP, Address: s}
+		}
+		if seen[a] {
+			return nil, fmt.Errorf("listen address %q is given twice", s)
+		}
+		seen[a] = true
+		addrs = append(addrs, a)
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no listen address")
+	}
+	return addrs, nil
+}
+
+// FirstTCP: 목록의 첫 TCP 주소 (없으면 "")
+func FirstTCP(addrs []Addr) string {
+	for _, a := range addrs {
+		if a.Network == NetworkTCP {
+			return a.Address
+		}
+	}
+	return ""
+}
+
+// SocketOptions: 서버가 만드는 Unix 소켓 파일의 권한과 소유자 (systemd가 만든 소켓에는 적용하지 않음)
+type SocketOptions struct {
+	Mode  fs.FileMode
+	Owner string // "user", "user:group" 또는 ":group" (이름 또는 숫자 ID, 비어 있으면 바꾸지 않음)
+}
+
+// ParseMode: 8진수 권한 문자열(예: "0660")을 해석합니다.
+func ParseMode(s string) (fs.FileMode, error) {
+	m, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || m > 0o777 {
+		return 0, fmt.Errorf("socket mode %q: want an octal permission such as 0660", s)
+	}
+	return fs.FileMode(m), nil
+}
+
+// Listen: 주소 목록의 리스너를 모두 엽니다. 하나라도 실패하면 이미 연 리스너를 닫고 오류를 반환합니다.
+// systemd 주소 하나가 여러 소켓에 해당할 수 있으므로 리스너 수는 주소 수보다 많을 수 있습니다.
+func Listen(addrs []Addr, opts SocketOptions) ([]net.Listener, error) {
+	var ls []net.Listener
+	fail := func(err error) ([]net.Listener, error) {
+		for _, l := range ls {
+			l.Close()
+		}
+		return nil, err
+	}
+	for _, a := range addrs {
+		switch a.Network {
+		case NetworkTCP:
+			l, err := net.Listen("tcp", a.Address)
+			if err != nil {
+				return fail(err)
+			}
+			ls = append(ls, l)
+		case NetworkUnix:
+			l, err := listenUnix(a.Address, opts)
+			if err != nil {
+				return fail(err)
+			}
+			ls = append(ls, l)
+		case NetworkSystemd:
+			found, err := takeActivated(a.Address)
+			if err != nil {
+				return fail(err)
+			}
+			ls = append(ls, found...)
+		default:
+			return fail(fmt.Errorf("unknown listen network %q", a.Network))
+		}
+	}
+	return ls, nil
+}
+
+// listenUnix: Unix 소켓을 만들고 권한과 소유자를 지정합니다. 닫으면 소켓 파일도 지웁니다.
+func listenUnix(path string, opts SocketOptions) (net.Listener, error) {
+	if err := removeStale(path); err != nil {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	mode := opts.Mode
+	if mode == 0 {
+		mode = DefaultSocketMode
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("set socket mode: %w", err)
+	}
+	if opts.Owner != "" {
+		uid, gid, err := lookupOwner(opts.Owner)
+		if err == nil {
+			err = os.Chown(path, uid, gid)
+		}
+		if err != nil {
+			l.Close()
+			return nil, fmt.Errorf("set socket owner %q: %w", opts.Owner, err)
+		}
+	}
+	return l, nil
+}
+
+// removeStale: 이전 실행이 비정상 종료하며 남긴 소켓 파일을 지웁니다.
+// 소켓이 아닌 파일이거나 다른 서버가 아직 받고 있는 소켓이면 지우지 않고 오류를 반환합니다.
+func removeStale(path string) error {
+	fi, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if c, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		c.Close()
+		return fmt.Errorf("%s: another server is already listening", path)
+	}
+	return os.Remove(path)
+}
+
+// lookupOwner: "user[:group]"을 uid, gid로 바꿉니다. 지정하지 않은 쪽은 -1(바꾸지 않음)입니다.
+func lookupOwner(owner string) (uid, gid int, err error) {
+	name, group, _ := strings.Cut(owner, ":")
+	uid, gid = -1, -1
+	if name != "" {
+		if uid, err = strconv.Atoi(name); err != nil {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return 0, 0, err
+			}
+			if uid, err = strconv.Atoi(u.Uid); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	if group != "" {
+		if gid, err = strconv.Atoi(group); err != nil {
+			g, err := user.LookupGroup(group)
+			if err != nil {
+				return 0, 0, err
+			}
+			if gid, err = strconv.Atoi(g.Gid); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	return uid, gid, nil
+}
@@ -0,0 +1,97 @@
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// listenFDsStart: systemd가 전달하는 첫 파일 디스크립터 번호 (SD_LISTEN_FDS_START)
+const listenFDsStart = 3
+
+// activatedListener: systemd에게서 받은 소켓 하나
+type activatedListener struct {
+	name  string // LISTEN_FDNAMES의 이름 (.socket 유닛의 FileDescriptorName, 기본값은 유닛 이름)
+	l     net.Listener
+	taken bool // 이미 어떤 주소에 배정되었는지 (같은 소켓을 두 번 Serve하지 않도록)
+}
+
+var activation struct {
+	once      sync.Once
+	mu        sync.Mutex
+	listeners []*activatedListener
+	err       error
+}
+
+// activated: 소켓 활성화 환경 변수를 한 번만 읽어 리스너로 바꿉니다.
+// 자식 프로세스가 같은 소켓을 물려받았다고 오해하지 않도록 환경 변수는 지웁니다.
+func activated() ([]*activatedListener, error) {
+	activation.once.Do(func() {
+		defer func() {
+			os.Unsetenv("LISTEN_PID")
+			os.Unsetenv("LISTEN_FDS")
+			os.Unsetenv("LISTEN_FDNAMES")
+		}()
+		pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+		if err != nil || pid != os.Getpid() {
+			return // 이 프로세스에 전달된 소켓이 아님
+		}
+		n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+		if err != nil || n <= 0 {
+			activation.err = fmt.Errorf("invalid LISTEN_FDS %q", os.Getenv("LISTEN_FDS"))
+			return
+		}
+		names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+		for i := 0; i < n; i++ {
+			name := "unknown" // systemd가 이름을 주지 않았을 때의 기본값과 같음
+			if i < len(names) && names[i] != "" {
+				name = names[i]
+			}
+			f := os.NewFile(uintptr(listenFDsStart+i), name)
+			l, err := net.FileListener(f) // 디스크립터를 복제하므로 원본은 닫음
+			f.Close()
+			if err != nil {
+				activation.err = fmt.Errorf("systemd socket %d (%s): %w", listenFDsStart+i, name, err)
+				return
+			}
+			activation.listeners = append(activation.listeners, &activatedListener{name: name, l: l})
+		}
+	})
+	return activation.listeners, activation.err
+}
+
+// takeActivated: 이름이 name인(비어 있으면 아직 배정되지 않은 모든) systemd 소켓을 가져옵니다.
+func takeActivated(name string) ([]net.Listener, error) {
+	all, err := activated()
+	if err != nil {
+		return nil, err
+	}
+	if len(all) == 0 {
+		return nil, errors.New("no sockets passed by systemd (LISTEN_FDS is not set for this process)")
+	}
+	activation.mu.Lock()
+	defer activation.mu.Unlock()
+	var ls []net.Listener
+	for _, a := range all {
+		if a.taken || (name != "" && a.name != name) {
+			continue
+		}
+		a.taken = true
+		ls = append(ls, a.l)
+	}
+	if len(ls) == 0 {
+		if name != "" {
+			names := make([]string, len(all))
+			for i, a := range all {
+				names[i] = a.name
+			}
+			return nil, fmt.Errorf("no unused systemd socket named %q (passed: %s)", name, strings.Join(names, ", "))
+		}
+		return nil, errors.New("all systemd sockets are already in use")
+	}
+	return ls, nil
+}
@@ -0,0 +1,386 @@
+// Package metrics는 외부 라이브러리 없이 카운터, 게이지, 히스토그램(레이블 포함)을 수집하고
+// Prometheus 텍스트 노출 형식 0.0.4로 출력합니다. (https://prometheus.io/docs/instrumenting/exposition_formats/)
+//
+// 메트릭 이름과 레이블 이름이 잘못되었거나 같은 이름을 두 번 등록하면 panic합니다. (프로그램 시작 시 등록하는 용도)
+// 레이블 값은 요청마다 달라지는 값(IP, ID 등)이 아닌 정해진 값만 사용해야 시계열 수가 무한히 늘지 않습니다.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ContentType: 텍스트 노출 형식 0.0.4의 Content-Type
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets: 요청 처리 시간(초)용 기본 히스토그램 구간
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var (
+	metricNameRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRE  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// 메트릭 종류 (# TYPE 줄)
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// Registry: 등록된 메트릭 모음
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// NewRegistry: 빈 Registry를 만듭니다.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// family: 이름이 같은 메트릭의 레이블 조합별 시계열 모음
+type family struct {
+	name    string
+	help    string
+	typ     string
+	labels  []string
+	buckets []float64      // 히스토그램 구간 상한 (오름차순)
+	fn      func() float64 // GaugeFunc: 출력할 때 값을 계산
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series: 레이블 값 하나의 조합에 대한 값
+type series struct {
+	values []string
+	val    atomicFloat // 카운터, 게이지
+	hist   *histogram  // 히스토그램
+}
+
+// register: 이름과 레이블을 검증하고 family를 추가합니다.
+func (reg *Registry) register(name, help, typ string, labels []string, buckets []float64) *family {
+	if !metricNameRE.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	for _, l := range labels {
+		if !labelNameRE.MatchString(l) || strings.HasPrefix(l, "__") || (typ == typeHistogram && l == "le") {
+			panic(fmt.Sprintf("metrics: invalid label name %q for %s", l, name))
+		}
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if _, dup := reg.families[name]; dup {
+		panic(fmt.Sprintf("metrics: %s registered twice", name))
+	}
+	f := &family{name: name, help: help, typ: typ, labels: labels, buckets: buckets, series: make(map[string]*series)}
+	reg.families[name] = f
+	return f
+}
+
+// with: 레이블 값 조합의 시계열 (없으면 생성)
+func (f *family) with(values []string) *series {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s wants %d label value(s) %v, got %d", f.name, len(f.labels), f.labels, len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), values...)}
+		if f.typ == typeHistogram {
+			s.hist = &histogram{counts: make([]uint64, len(f.buckets))}
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// atomicFloat: 잠금 없이 더하고 설정할 수 있는 float64
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (a *atomicFloat) load() float64 {
+	return math.Float64frombits(a.bits.Load())
+}
+
+func (a *atomicFloat) store(v float64) {
+	a.bits.Store(math.Float64bits(v))
+}
+
+func (a *atomicFloat) add(v float64) {
+	for {
+		old := a.bits.Load()
+		if a.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// =================================================================
+// 카운터
+// =================================================================
+
+// Counter: 증가만 하는 값 (요청 수, 오류 수 등)
+type Counter struct{ s *series }
+
+// Inc: 1 증가
+func (c *Counter) Inc() { c.s.val.add(1) }
+
+// Add: v(0 이상)만큼 증가
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.s.val.add(v)
+}
+
+// CounterVec: 레이블로 구분되는 카운터 모음
+type CounterVec struct{ f *family }
+
+// NewCounterVec: 레이블이 있는 카운터를 등록합니다.
+func (reg *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{f: reg.register(name, help, typeCounter, labels, nil)}
+}
+
+// NewCounter: 레이블이 없는 카운터를 등록합니다.
+func (reg *Registry) NewCounter(name, help string) *Counter {
+	return reg.NewCounterVec(name, help).With()
+}
+
+// With: 레이블 값(등록한 레이블 순서)에 해당하는 카운터
+func (v *CounterVec) With(values ...string) *Counter {
+	return &Counter{s: v.f.with(values)}
+}
+
+// =================================================================
+// 게이지
+// =================================================================
+
+// Gauge: 오르내리는 현재 값 (처리 중인 요청 수 등)
+type Gauge struct{ s *series }
+
+// Set: 값을 설정
+func (g *Gauge) Set(v float64) { g.s.val.store(v) }
+
+// Add: v만큼 더함 (음수 가능)
+func (g *Gauge) Add(v float64) { g.s.val.add(v) }
+
+// Inc: 1 증가
+func (g *Gauge) Inc() { g.s.val.add(1) }
+
+// Dec: 1 감소
+func (g *Gauge) Dec() { g.s.val.add(-1) }
+
+// GaugeVec: 레이블로 구분되는 게이지 모음
+type GaugeVec struct{ f *family }
+
+// NewGaugeVec: 레이블이 있는 게이지를 등록합니다.
+func (reg *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{f: reg.register(name, help, typeGauge, labels, nil)}
+}
+
+// NewGauge: 레이블이 없는 게이지를 등록합니다.
+func (reg *Registry) NewGauge(name, help string) *Gauge {
+	return reg.NewGaugeVec(name, help).With()
+}
+
+// With: 레이블 값에 해당하는 게이지
+func (v *GaugeVec) With(values ...string) *Gauge {
+	return &Gauge{s: v.f.with(values)}
+}
+
+// NewGaugeFunc: 출력할 때마다 fn을 호출해 값을 정하는 게이지를 등록합니다. (회원 수처럼 다른 곳에 있는 값)
+func (reg *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	f := reg.register(name, help, typeGauge, nil, nil)
+	f.fn = fn
+}
+
+// =================================================================
+// 히스토그램
+// =================================================================
+
+// histogram: 구간별 관측 수, 합계, 개수
+type histogram struct {
+	mu     sync.Mutex
+	counts []uint64 // 구간별 (누적 아님)
+	sum    float64
+	count  uint64
+}
+
+// Histogram: 관측값의 분포 (처리 시간 등)
+type Histogram struct {
+	s       *series
+	buckets []float64
+}
+
+// Observe: 값 하나를 기록
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.buckets, v) // v <= buckets[i]인 첫 구간
+	hist := h.s.hist
+	hist.mu.Lock()
+	defer hist.mu.Unlock()
+	if i < len(hist.counts) {
+		hist.counts[i]++
+	}
+	hist.sum += v
+	hist.count++
+}
+
+// HistogramVec: 레이블로 구분되는 히스토그램 모음
+type HistogramVec struct{ f *family }
+
+// NewHistogramVec: 레이블이 있는 히스토그램을 등록합니다. buckets가 nil이면 DefaultBuckets를 사용합니다.
+func (reg *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	if n := len(b); n > 0 && math.IsInf(b[n-1], 1) {
+		b = b[:n-1] // +Inf 구간은 항상 출력
+	}
+	return &HistogramVec{f: reg.register(name, help, typeHistogram, labels, b)}
+}
+
+// NewHistogram: 레이블이 없는 히스토그램을 등록합니다.
+func (reg *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	return reg.NewHistogramVec(name, help, buckets).With()
+}
+
+// With: 레이블 값에 해당하는 히스토그램
+func (v *HistogramVec) With(values ...string) *Histogram {
+	return &Histogram{s: v.f.with(values), buckets: v.f.buckets}
+}
+
+// =================================================================
+// 텍스트 노출 형식
+// =================================================================
+
+// formatFloat: 샘플 값 표기 (+Inf, -Inf, NaN 포함)
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// writeSample: `name{l1="v1",...} value` 한 줄
+func writeSample(b *bytes.Buffer, name string, labels, values []string, extraName, extraValue string, v string) {
+	b.WriteString(name)
+	if len(labels) > 0 || extraName != "" {
+		b.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(b, `%s="%s"`, l, labelEscaper.Replace(values[i]))
+		}
+		if extraName != "" {
+			if len(labels) > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(b, `%s="%s"`, extraName, extraValue)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(v)
+	b.WriteByte('\n')
+}
+
+// write: family 하나를 출력합니다. 시계열은 레이블 값 순서로 정렬합니다.
+func (f *family) write(b *bytes.Buffer) {
+	fmt.Fprintf(b, "# HELP %s %s\n", f.name, helpEscaper.Replace(f.help))
+	fmt.Fprintf(b, "# TYPE %s %s\n", f.name, f.typ)
+	if f.fn != nil {
+		writeSample(b, f.name, nil, nil, "", "", formatFloat(f.fn()))
+		return
+	}
+
+	f.mu.Lock()
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	all := make([]*series, 0, len(keys))
+	sort.Strings(keys)
+	for _, k := range keys {
+		all = append(all, f.series[k])
+	}
+	f.mu.Unlock()
+
+	for _, s := range all {
+		if s.hist == nil {
+			writeSample(b, f.name, f.labels, s.values, "", "", formatFloat(s.val.load()))
+			continue
+		}
+		s.hist.mu.Lock()
+		counts := append([]uint64(nil), s.hist.counts...)
+		sum, count := s.hist.sum, s.hist.count
+		s.hist.mu.Unlock()
+
+		var cumulative uint64
+		for i, upper := range f.buckets {
+			cumulative += counts[i]
+			writeSample(b, f.name+"_bucket", f.labels, s.values, "le", formatFloat(upper), strconv.FormatUint(cumulative, 10))
+		}
+		writeSample(b, f.name+"_bucket", f.labels, s.values, "le", "+Inf", strconv.FormatUint(count, 10))
+		writeSample(b, f.name+"_sum", f.labels, s.values, "", "", formatFloat(sum))
+		writeSample(b, f.name+"_count", f.labels, s.values, "", "", strconv.FormatUint(count, 10))
+	}
+}
+
+// WriteText: 모든 메트릭을 이름 순서로 텍스트 노출 형식으로 출력합니다.
+func (reg *Registry) WriteText(w io.Writer) error {
+	reg.mu.Lock()
+	fams := make([]*family, 0, len(reg.families))
+	for _, f := range reg.families {
+		fams = append(fams, f)
+	}
+	reg.mu.Unlock()
+	sort.Slice(fams, func(i, j int) bool { return fams[i].name < fams[j].name })
+
+	var b bytes.Buffer
+	for _, f := range fams {
+		f.write(&b)
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// Handler: /metrics 핸들러
+func (reg *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		w.Header().Set("Cache-Control", "no-store")
+		reg.WriteText(w)
+	})
+}
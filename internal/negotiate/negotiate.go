@@ -0,0 +1,168 @@
+// Package negotiate는 Accept 헤더와 ?format= 파라미터를 이용한 응답 형식(HTML/JSON/텍스트) 협상을 제공합니다.
+package negotiate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Format: 응답 형식
+type Format string
+
+const (
+	HTML Format = "html"
+	JSON Format = "json"
+	Text Format = "text"
+)
+
+// Formats: 서버가 지원하는 형식 (Accept의 q 값이 같으면 앞쪽을 우선)
+var Formats = []Format{HTML, JSON, Text}
+
+// ErrNotAcceptable: 클라이언트가 지원 형식 중 어느 것도 허용하지 않을 때의 오류
+var ErrNotAcceptable = errors.New("none of text/html, application/json or text/plain is acceptable")
+
+// ContentType: 형식에 해당하는 Content-Type 헤더 값
+func (f Format) ContentType() string {
+	switch f {
+	case JSON:
+		return "application/json; charset=utf-8"
+	case Text:
+		return "text/plain; charset=utf-8"
+	default:
+		return "text/html; charset=utf-8"
+	}
+}
+
+// mediaType: 형식의 MIME 타입 (Accept 헤더 비교용)
+func (f Format) mediaType() string {
+	return strings.SplitN(f.ContentType(), ";", 2)[0]
+}
+
+// ParseFormat: ?format= 파라미터 값을 Format으로 변환합니다.
+func ParseFormat(s string) (Format, bool) {
+	switch strings.ToLower(s) {
+	case "html":
+		return HTML, true
+	case "json":
+		return JSON, true
+	case "text", "txt", "plain":
+		return Text, true
+	}
+	return "", false
+}
+
+// FromRequest: 요청에 맞는 응답 형식을 결정합니다.
+// ?format= 파라미터가 Accept 헤더보다 우선하며, 둘 다 없으면 HTML을 사용합니다.
+func FromRequest(r *http.Request) (Format, error) {
+	if v := r.URL.Query().Get("format"); v != "" {
+		if f, ok := ParseFormat(v); ok {
+			return f, nil
+		}
+		return HTML, errors.New("unknown format " + strconv.Quote(v) + ", expected html, json or text")
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return HTML, nil
+	}
+	return fromAccept(accept)
+}
+
+// acceptRange: Accept 헤더의 미디어 범위 하나 (예: text/*;q=0.8)
+type acceptRange struct {
+	typ, sub string
+	q        float64
+}
+
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		mt := strings.ToLower(strings.TrimSpace(fields[0]))
+		typ, sub, ok := strings.Cut(mt, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(strings.TrimSpace(k), "q") {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		ranges = append(ranges, acceptRange{typ: typ, sub: sub, q: q})
+	}
+	return ranges
+}
+
+// fromAccept: 가장 구체적으로 일치하는 범위의 q 값으로 각 형식을 평가하여 최선의 형식을 고릅니다.
+func fromAccept(accept string) (Format, error) {
+	ranges := parseAccept(accept)
+
+	best, bestQ := Format(""), 0.0
+	for _, f := range Formats {
+		typ, sub, _ := strings.Cut(f.mediaType(), "/")
+		q, specificity := 0.0, -1
+		for _, ar := range ranges {
+			s := -1
+			switch {
+			case ar.typ == typ && ar.sub == sub:
+				s = 2
+			case ar.typ == typ && ar.sub == "*":
+				s = 1
+			case ar.typ == "*" && ar.sub == "*":
+				s = 0
+			}
+			if s > specificity {
+				q, specificity = ar.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = f, q
+		}
+	}
+	if best == "" {
+		return HTML, ErrNotAcceptable
+	}
+	return best, nil
+}
+
+// writeHeader: 공통 응답 헤더를 설정합니다.
+func writeHeader(w http.ResponseWriter, f Format, status int) {
+	w.Header().Set("Content-Type", f.ContentType())
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+}
+
+// WriteJSON: v를 JSON으로 인코딩하여 응답합니다.
+func WriteJSON(w http.ResponseWriter, status int, v any) error {
+	writeHeader(w, JSON, status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+// WriteText: 텍스트 응답을 작성합니다. (끝에 줄바꿈 추가)
+func WriteText(w http.ResponseWriter, status int, text string) error {
+	writeHeader(w, Text, status)
+	_, err := io.WriteString(w, text+"\n")
+	return err
+}
+
+// WriteHTML: html/template을 실행하여 응답합니다.
+// 템플릿 실행이 실패하면 아무것도 쓰지 않고 오류를 반환합니다.
+func WriteHTML(w http.ResponseWriter, status int, tmpl *template.Template, data any) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	writeHeader(w, HTML, status)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
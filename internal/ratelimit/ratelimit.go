@@ -0,0 +1,374 @@
+// Package ratelimit는 클라이언트별 토큰 버킷 요청 속도 제한과 서버 전체의 동시 처리 요청 수 제한을 제공합니다.
+// 제한을 넘은 요청은 429(Retry-After, RateLimit-* 헤더 포함) 또는 503으로 거절합니다.
+package ratelimit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAPIKeyHeader: apikey 기준에서 API 키를 읽는 헤더
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// minIdleTTL: 사용하지 않는 버킷을 지우기까지의 최소 시간
+const minIdleTTL = time.Minute
+
+// DefaultMaxBuckets: 동시에 유지하는 버킷 수의 기본 상한
+const DefaultMaxBuckets = 100000
+
+// maxAPIKeyLen: API 키로 인정하는 최대 길이
+const maxAPIKeyLen = 256
+
+// overflowKey: 버킷 수가 상한에 이르렀을 때 새 키들이 함께 쓰는 버킷
+const overflowKey = "overflow"
+
+// 버킷 구분 기준 (Config.Key에 쉼표로 조합 가능, 예: "ip,route")
+const (
+	KeyIP     = "ip"
+	KeyAPIKey = "apikey"
+	KeyRoute  = "route"
+)
+
+// ErrorHandler: 거절 응답(429/503)을 출력하는 함수
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, status int, msg string)
+
+// Config: 속도 제한 설정
+type Config struct {
+	Rate         float64       // 초당 허용 요청 수 (버킷이 채워지는 속도)
+	Burst        int           // 버킷 크기: 한꺼번에 허용하는 최대 요청 수 (0이면 Rate를 올림한 값)
+	Key          string        // 버킷 구분 기준: ip, apikey, route 또는 쉼표로 조합 (기본 ip)
+	APIKeyHeader string        // apikey 기준에서 사용할 헤더 (기본 X-API-Key)
+	APIKeys      []string      // apikey 기준에서 인정하는 API 키 (목록에 없는 키는 클라이언트 IP로 구분)
+	MaxBuckets   int           // 동시에 유지하는 버킷 수 상한 (0이면 DefaultMaxBuckets)
+	IdleTTL      time.Duration // 이 시간 동안 쓰이지 않은 버킷은 삭제 (0이면 버킷이 다시 가득 차는 시간, 최소 1분)
+}
+
+// bucket: 키 하나의 토큰 버킷
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter: 키별 토큰 버킷 속도 제한 미들웨어
+type Limiter struct {
+	RenderError ErrorHandler // nil이면 http.Error 사용
+
+	rate   float64
+	burst  float64
+	keys   []string
+	header string
+	known  map[[sha256.Size]byte]bool // 인정하는 API 키의 SHA-256 (원문을 메모리에 두지 않고 길이와 무관하게 비교)
+	routes *http.ServeMux
+	limit  int // 버킷 수 상한
+	ttl    time.Duration
+	policy string           // RateLimit-Policy 값
+	now    func() time.Time // 현재 시각 (테스트에서 교체)
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// New: 설정을 검증하고 Limiter를 만듭니다.
+// route 기준은 routes(ServeMux)의 등록 패턴으로 요청을 구분하며, routes가 nil이면 URL 경로를 사용합니다.
+func New(cfg Config, routes *http.ServeMux) (*Limiter, error) {
+	if cfg.Rate <= 0 || math.IsInf(cfg.Rate, 0) || math.IsNaN(cfg.Rate) {
+		return nil, fmt.Errorf("rate limit must be a positive number of requests per second, got %v", cfg.Rate)
+	}
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(cfg.Rate))
+	}
+	key := cfg.Key
+	if key == "" {
+		key = KeyIP
+	}
+	var keys []string
+	for _, k := range strings.Split(key, ",") {
+		k = strings.ToLower(strings.TrimSpace(k))
+		switch k {
+		case KeyIP, KeyAPIKey, KeyRoute:
+			keys = append(keys, k)
+		default:
+			return nil, fmt.Errorf("unknown rate limit key %q (want ip, apikey, route or a comma-separated combination)", k)
+		}
+	}
+	header := cfg.APIKeyHeader
+	if header == "" {
+		header = DefaultAPIKeyHeader
+	}
+	known := make(map[[sha256.Size]byte]bool, len(cfg.APIKeys))
+	for i, k := range cfg.APIKeys {
+		if !wellFormed(k) {
+			// 키 값은 비밀이므로 오류 메시지에 넣지 않음
+			return nil, fmt.Errorf("API key #%d is not well-formed (want 1-%d visible ASCII characters)", i+1, maxAPIKeyLen)
+		}
+		known[sha256.Sum256([]byte(k))] = true
+	}
+	if len(known) == 0 && slices.Contains(keys, KeyAPIKey) {
+		return nil, errors.New("rate limit key apikey requires a list of known API keys (-rate-api-keys)")
+	}
+	limit := cfg.MaxBuckets
+	if limit <= 0 {
+		limit = DefaultMaxBuckets
+	}
+	ttl := cfg.IdleTTL
+	if ttl <= 0 {
+		ttl = max(time.Duration(float64(burst)/cfg.Rate*float64(time.Second)), minIdleTTL)
+	}
+	window := int(math.Ceil(float64(burst) / cfg.Rate))
+
+	return &Limiter{
+		rate:    cfg.Rate,
+		burst:   float64(burst),
+		keys:    keys,
+		header:  header,
+		known:   known,
+		routes:  routes,
+		limit:   limit,
+		ttl:     ttl,
+		policy:  fmt.Sprintf("%d;w=%d", burst, window),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}, nil
+}
+
+// ClientIP: 요청한 클라이언트의 IP 주소 (RemoteAddr에서 포트를 뗀 값)
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// key: 요청이 속한 버킷의 키
+func (l *Limiter) key(r *http.Request) string {
+	parts := make([]string, 0, len(l.keys))
+	for _, k := range l.keys {
+		switch k {
+		case KeyIP:
+			parts = append(parts, "ip="+ClientIP(r))
+		case KeyAPIKey:
+			if id, ok := l.apiKeyID(r.Header.Get(l.header)); ok {
+				parts = append(parts, "key="+id)
+			} else {
+				// 키가 없거나 인정하지 않는 키: 키를 바꿔 가며 제한을 피하지 못하도록 IP로 구분
+				parts = append(parts, "anon="+ClientIP(r))
+			}
+		case KeyRoute:
+			parts = append(parts, "route="+l.route(r))
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// apiKeyID: 인정하는 API 키이면 버킷 키로 쓸 식별자(SHA-256 앞부분)를 반환합니다.
+func (l *Limiter) apiKeyID(apiKey string) (string, bool) {
+	if !wellFormed(apiKey) {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	if !l.known[sum] {
+		return "", false
+	}
+	return hex.EncodeToString(sum[:8]), true
+}
+
+// wellFormed: API 키로 인정할 수 있는 형식인지 (길이 제한, 공백·제어 문자가 없는 ASCII)
+func wellFormed(apiKey string) bool {
+	if apiKey == "" || len(apiKey) > maxAPIKeyLen {
+		return false
+	}
+	for i := 0; i < len(apiKey); i++ {
+		if c := apiKey[i]; c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// route: 요청을 처리할 ServeMux 패턴 (예: "/membership_api/")
+func (l *Limiter) route(r *http.Request) string {
+	if l.routes != nil {
+		if _, pattern := l.routes.Handler(r); pattern != "" {
+			return pattern
+		}
+	}
+	return r.URL.Path
+}
+
+// take: key의 버킷에서 토큰 하나를 꺼냅니다. 남은 토큰 수와 함께 허용 여부를 반환합니다.
+func (l *Limiter) take(key string) (allowed bool, tokens float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now, false)
+	b, ok := l.buckets[key]
+	if !ok && len(l.buckets) >= l.limit {
+		// 상한에 이르면 쓰이지 않은 버킷을 먼저 지워 보고, 그래도 가득 차 있으면 새 키들은 버킷 하나를 함께 씀
+		l.sweep(now, true)
+		if len(l.buckets) >= l.limit {
+			key = overflowKey
+			b, ok = l.buckets[key]
+		}
+	}
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, b.tokens
+	}
+	return false, b.tokens
+}
+
+// sweep: IdleTTL 동안 쓰이지 않은 버킷을 지웁니다.
+// 호출 빈도를 줄이기 위해 ttl/2마다 한 번, 버킷이 가득 찼을 때(full)는 최대 1초에 한 번 실행합니다.
+func (l *Limiter) sweep(now time.Time, full bool) {
+	interval := l.ttl / 2
+	if full {
+		interval = min(interval, time.Second)
+	}
+	if now.Sub(l.lastSweep) < interval {
+		return
+	}
+	l.lastSweep = now
+	for k, b := range l.buckets {
+		if now.Sub(b.last) > l.ttl {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// Buckets: 현재 유지 중인 버킷 수
+func (l *Limiter) Buckets() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// seconds: 초 단위로 올림한 값 (헤더용)
+func seconds(s float64) string {
+	return strconv.Itoa(int(math.Ceil(s)))
+}
+
+// Middleware: 요청마다 토큰을 하나 쓰고, 없으면 429로 거절합니다.
+// 모든 응답에 RateLimit-Limit/Remaining/Reset/Policy 헤더를 붙입니다.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, tokens := l.take(l.key(r))
+
+		h := w.Header()
+		h.Set("RateLimit-Policy", l.policy)
+		h.Set("RateLimit-Limit", strconv.Itoa(int(l.burst)))
+		h.Set("RateLimit-Remaining", strconv.Itoa(int(math.Floor(tokens))))
+		h.Set("RateLimit-Reset", seconds((l.burst-tokens)/l.rate)) // 버킷이 다시 가득 차기까지 남은 초
+
+		if !allowed {
+			retry := seconds((1 - tokens) / l.rate)
+			h.Set("Retry-After", retry)
+			l.reject(w, r, http.StatusTooManyRequests, "Too many requests. Retry after "+retry+" second(s).")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (l *Limiter) reject(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	if l.RenderError != nil {
+		l.RenderError(w, r, status, msg)
+		return
+	}
+	http.Error(w, msg, status)
+}
+
+// InFlight: 서버 전체에서 동시에 처리하는 요청 수 제한. 넘치면 기다리지 않고 바로 503으로 거절합니다(부하 차단).
+// 모든 요청이 자리를 차지하며, SSE나 WebSocket처럼 계속 열려 있는 요청은 스트림이 실제로 시작되면
+// (이벤트 스트림 응답을 처음 전송하거나 연결을 가로채면) 자리를 돌려주어 짧은 요청의 자리를 막지 않습니다.
+// 요청 헤더(Accept, Upgrade)는 클라이언트가 마음대로 보낼 수 있으므로 판단에 쓰지 않습니다.
+type InFlight struct {
+	RenderError ErrorHandler // nil이면 http.Error 사용
+
+	sem chan struct{}
+}
+
+// NewInFlight: 동시에 최대 limit개의 요청을 처리하는 InFlight를 만듭니다.
+func NewInFlight(limit int) *InFlight {
+	return &InFlight{sem: make(chan struct{}, limit)}
+}
+
+// Current: 지금 처리 중인 요청 수 (스트림으로 바뀐 요청은 제외)
+func (f *InFlight) Current() int {
+	return len(f.sem)
+}
+
+// Middleware: 자리가 있으면 요청을 처리하고, 없으면 503과 Retry-After로 거절합니다.
+func (f *InFlight) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case f.sem <- struct{}{}:
+			var once sync.Once
+			release := func() { once.Do(func() { <-f.sem }) }
+			defer release()
+			next.ServeHTTP(&streamWriter{ResponseWriter: w, release: release}, r)
+		default:
+			w.Header().Set("Retry-After", "1")
+			msg := "Server is busy. Please retry shortly."
+			if f.RenderError != nil {
+				f.RenderError(w, r, http.StatusServiceUnavailable, msg)
+				return
+			}
+			http.Error(w, msg, http.StatusServiceUnavailable)
+		}
+	})
+}
+
+// streamWriter: 응답이 스트림으로 바뀌면(text/event-stream 응답 전송, 연결 가로채기) release를 호출하는 래퍼
+type streamWriter struct {
+	http.ResponseWriter
+	release func()
+}
+
+// FlushError: 전송한 응답이 이벤트 스트림이면 동시 요청 자리를 돌려줍니다.
+func (sw *streamWriter) FlushError() error {
+	err := http.NewResponseController(sw.ResponseWriter).Flush()
+	if err == nil && strings.HasPrefix(sw.Header().Get("Content-Type"), "text/event-stream") {
+		sw.release()
+	}
+	return err
+}
+
+// Flush: http.Flusher
+func (sw *streamWriter) Flush() {
+	sw.FlushError()
+}
+
+// Hijack: 연결을 가로채면(WebSocket) 동시 요청 자리를 돌려줍니다.
+func (sw *streamWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(sw.ResponseWriter).Hijack()
+	if err == nil {
+		sw.release()
+	}
+	return conn, brw, err
+}
+
+// Unwrap: http.ResponseController가 원래 ResponseWriter에 접근할 수 있도록 합니다. (쓰기 기한 설정 등)
+func (sw *streamWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
@@ -0,0 +1,272 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock: 테스트에서 시간을 직접 진행시키는 시계
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTestLimiter: 가짜 시계를 쓰는 Limiter와 그 미들웨어
+func newTestLimiter(t *testing.T, cfg Config) (*Limiter, http.Handler, *fakeClock) {
+	t.Helper()
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	mux.HandleFunc("/a/", ok)
+	mux.HandleFunc("/b", ok)
+	l, err := New(cfg, mux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l.now = clock.now
+	return l, l.Middleware(mux), clock
+}
+
+// call: remote 주소에서 path로 요청을 보냅니다. (apiKey가 비어 있지 않으면 X-API-Key 포함)
+func call(h http.Handler, remote, path, apiKey string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", path, nil)
+	r.RemoteAddr = remote
+	if apiKey != "" {
+		r.Header.Set(DefaultAPIKeyHeader, apiKey)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"defaults", Config{Rate: 1}, false},
+		{"combined keys", Config{Rate: 1, Key: "ip, route"}, false},
+		{"apikey with keys", Config{Rate: 1, Key: "apikey", APIKeys: []string{"alpha"}}, false},
+		{"zero rate", Config{Rate: 0}, true},
+		{"negative rate", Config{Rate: -1}, true},
+		{"unknown key", Config{Rate: 1, Key: "ip,user"}, true},
+		{"apikey without keys", Config{Rate: 1, Key: "apikey"}, true},
+		{"malformed API key", Config{Rate: 1, Key: "apikey", APIKeys: []string{"has space"}}, true},
+		{"empty API key", Config{Rate: 1, Key: "apikey", APIKeys: []string{""}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBucketRefill(t *testing.T) {
+	// 초당 2개, 버킷 3개
+	_, h, clock := newTestLimiter(t, Config{Rate: 2, Burst: 3})
+	steps := []struct {
+		advance    time.Duration
+		wantStatus int
+		remaining  string
+		reset      string
+		retryAfter string // 429일 때만
+	}{
+		{0, http.StatusOK, "2", "1", ""},
+		{0, http.StatusOK, "1", "1", ""},
+		{0, http.StatusOK, "0", "2", ""},
+		{0, http.StatusTooManyRequests, "0", "2", "1"},
+		{250 * time.Millisecond, http.StatusTooManyRequests, "0", "2", "1"}, // 토큰 0.5개
+		{250 * time.Millisecond, http.StatusOK, "0", "2", ""},               // 토큰 1개
+		{10 * time.Second, http.StatusOK, "2", "1", ""},                     // 버킷 크기까지만 채워짐
+	}
+	for i, s := range steps {
+		clock.advance(s.advance)
+		w := call(h, "192.0.2.1:1000", "/b", "")
+		got := w.Header()
+		if w.Code != s.wantStatus || got.Get("RateLimit-Remaining") != s.remaining ||
+			got.Get("RateLimit-Reset") != s.reset || got.Get("Retry-After") != s.retryAfter {
+			t.Errorf("step %d: status %d, Remaining %q, Reset %q, Retry-After %q; want %d, %q, %q, %q", i,
+				w.Code, got.Get("RateLimit-Remaining"), got.Get("RateLimit-Reset"), got.Get("Retry-After"),
+				s.wantStatus, s.remaining, s.reset, s.retryAfter)
+		}
+		if got.Get("RateLimit-Limit") != "3" || got.Get("RateLimit-Policy") != "3;w=2" {
+			t.Errorf("step %d: Limit %q, Policy %q", i, got.Get("RateLimit-Limit"), got.Get("RateLimit-Policy"))
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    float64
+		elapsed time.Duration // 버킷을 비운 뒤 지난 시간
+		want    string
+	}{
+		{"empty bucket", 0.1, 0, "10"},
+		{"partly refilled", 0.1, 3 * time.Second, "7"},
+		{"almost refilled", 0.1, 9900 * time.Millisecond, "1"},
+		{"fast rate rounds up", 100, 0, "1"},
+		{"one per minute", 1.0 / 60, 15 * time.Second, "45"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, h, clock := newTestLimiter(t, Config{Rate: tt.rate, Burst: 1})
+			if w := call(h, "192.0.2.1:1000", "/b", ""); w.Code != http.StatusOK {
+				t.Fatalf("first request: status %d", w.Code)
+			}
+			clock.advance(tt.elapsed)
+			w := call(h, "192.0.2.1:1000", "/b", "")
+			if w.Code != http.StatusTooManyRequests {
+				t.Fatalf("status %d, want 429", w.Code)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.want {
+				t.Errorf("Retry-After = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	type req struct {
+		remote, path, apiKey string
+		want                 int
+	}
+	const ok, limited = http.StatusOK, http.StatusTooManyRequests
+	tests := []struct {
+		name string
+		key  string
+		reqs []req
+	}{
+		{"ip", "ip", []req{
+			{"192.0.2.1:1000", "/a/", "", ok},
+			{"192.0.2.1:2000", "/b", "", limited}, // 포트가 달라도 같은 IP
+			{"192.0.2.2:1000", "/a/", "", ok},
+		}},
+		{"route uses ServeMux patterns", "route", []req{
+			{"192.0.2.1:1000", "/a/x", "", ok},
+			{"192.0.2.2:1000", "/a/y", "", limited}, // 같은 패턴 "/a/"
+			{"192.0.2.1:1000", "/b", "", ok},
+		}},
+		{"ip and route", "ip,route", []req{
+			{"192.0.2.1:1000", "/a/", "", ok},
+			{"192.0.2.1:1000", "/b", "", ok},
+			{"192.0.2.1:1000", "/a/", "", limited},
+			{"192.0.2.2:1000", "/a/", "", ok},
+		}},
+		{"known API keys get their own bucket", "apikey", []req{
+			{"192.0.2.1:1000", "/b", "alpha", ok},
+			{"192.0.2.2:1000", "/b", "alpha", limited}, // IP가 달라도 같은 키
+			{"192.0.2.1:1000", "/b", "beta", ok},
+		}},
+		{"unknown API keys fall back to the client IP", "apikey", []req{
+			{"192.0.2.1:1000", "/b", "x1", ok},
+			{"192.0.2.1:1000", "/b", "x2", limited},
+			{"192.0.2.1:1000", "/b", "", limited},
+			{"192.0.2.1:1000", "/b", "alpha", ok}, // 인정하는 키는 별도 버킷
+			{"192.0.2.2:1000", "/b", "x3", ok},
+		}},
+		{"malformed API keys fall back to the client IP", "apikey", []req{
+			{"192.0.2.1:1000", "/b", "bad key", ok},
+			{"192.0.2.1:1000", "/b", "bad\tkey", limited},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, h, _ := newTestLimiter(t, Config{Rate: 0.001, Burst: 1, Key: tt.key, APIKeys: []string{"alpha", "beta"}})
+			for i, r := range tt.reqs {
+				if w := call(h, r.remote, r.path, r.apiKey); w.Code != r.want {
+					t.Errorf("request %d (%s %s key %q): status %d, want %d", i, r.remote, r.path, r.apiKey, w.Code, r.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxBuckets(t *testing.T) {
+	l, h, clock := newTestLimiter(t, Config{Rate: 0.001, Burst: 1, MaxBuckets: 2, IdleTTL: time.Minute})
+	steps := []struct {
+		advance time.Duration
+		remote  string
+		want    int
+	}{
+		{0, "192.0.2.1:1", http.StatusOK},
+		{0, "192.0.2.2:1", http.StatusOK},
+		{0, "192.0.2.3:1", http.StatusOK},               // 상한: 새 키들이 함께 쓰는 버킷
+		{0, "192.0.2.4:1", http.StatusTooManyRequests},  // 같은 공용 버킷
+		{0, "192.0.2.1:1", http.StatusTooManyRequests},  // 기존 버킷은 그대로
+		{2 * time.Minute, "192.0.2.5:1", http.StatusOK}, // 쓰이지 않은 버킷을 지운 뒤 새 버킷
+	}
+	for i, s := range steps {
+		clock.advance(s.advance)
+		if w := call(h, s.remote, "/b", ""); w.Code != s.want {
+			t.Errorf("step %d (%s): status %d, want %d", i, s.remote, w.Code, s.want)
+		}
+		if n := l.Buckets(); n > 3 {
+			t.Fatalf("step %d: %d buckets, want at most MaxBuckets+1", i, n)
+		}
+	}
+}
+
+func TestInFlight(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		flush       bool
+		wantSecond  int
+	}{
+		{"slow request holds its slot", "text/plain", true, http.StatusServiceUnavailable},
+		{"unflushed event stream holds its slot", "text/event-stream", false, http.StatusServiceUnavailable},
+		{"started event stream releases its slot", "text/event-stream", true, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewInFlight(1)
+			started, finish := make(chan struct{}), make(chan struct{})
+			h := f.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/slow" {
+					return
+				}
+				w.Header().Set("Content-Type", tt.contentType)
+				if tt.flush {
+					w.(http.Flusher).Flush()
+				}
+				close(started)
+				<-finish
+			}))
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				call(h, "192.0.2.1:1", "/slow", "")
+			}()
+			<-started
+
+			// 요청 헤더로는 자리를 비켜 갈 수 없음
+			r := httptest.NewRequest("GET", "/fast", nil)
+			r.Header.Set("Accept", "text/event-stream")
+			r.Header.Set("Upgrade", "websocket")
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.wantSecond {
+				t.Errorf("second request: status %d, want %d", w.Code, tt.wantSecond)
+			}
+			if w.Code == http.StatusServiceUnavailable && w.Header().Get("Retry-After") != "1" {
+				t.Errorf("Retry-After = %q, want 1", w.Header().Get("Retry-After"))
+			}
+
+			close(finish)
+			wg.Wait()
+			if n := f.Current(); n != 0 {
+				t.Errorf("Current() = %d after all requests finished, want 0", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package rawhttp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testHandler: 두 엔진에 똑같이 연결하여 응답을 비교하는 핸들러
+func testHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "read error: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		headers := map[string][]string{}
+		for k, v := range r.Header {
+			headers[k] = v
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"method":        r.Method,
+			"uri":           r.RequestURI,
+			"path":          r.URL.Path,
+			"query":         r.URL.Query(),
+			"host":          r.Host,
+			"proto":         r.Proto,
+			"close":         r.Close,
+			"contentLength": r.ContentLength,
+			"te":            r.TransferEncoding,
+			"headers":       headers,
+			"body":          string(body),
+			"trailer":       r.Trailer,
+		})
+	})
+	mux.HandleFunc("/big", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		for i := 0; i < 100; i++ {
+			fmt.Fprintf(w, "%s\n", strings.Repeat(string(rune('a'+i%26)), 99))
+		}
+	})
+	mux.HandleFunc("/flush", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "first,")
+		w.(http.Flusher).Flush()
+		io.WriteString(w, "second")
+	})
+	mux.HandleFunc("/nocontent", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/length", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "5")
+		io.WriteString(w, "hello")
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Custom", "yes")
+		w.WriteHeader(http.StatusTeapot)
+		io.WriteString(w, "<html>short and stout</html>")
+	})
+	return mux
+}
+
+// startServers: 같은 핸들러로 rawhttp 서버와 net/http 서버를 시작합니다.
+func startServers(t *testing.T, maxHeaderBytes int) (rawAddr, stdAddr string) {
+	t.Helper()
+	handler := testHandler()
+
+	rl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs := &Server{Handler: handler, MaxHeaderBytes: maxHeaderBytes}
+	go rs.Serve(rl)
+	t.Cleanup(func() { rs.Close() })
+
+	sl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs := &http.Server{Handler: handler, MaxHeaderBytes: maxHeaderBytes}
+	go hs.Serve(sl)
+	t.Cleanup(func() { hs.Close() })
+
+	return rl.Addr().String(), sl.Addr().String()
+}
+
+// wireResponse: 비교에 쓰는 응답 요약 (Date 등 매번 달라지는 값은 제외)
+type wireResponse struct {
+	Status           int
+	Proto            string
+	ContentLength    int64
+	TransferEncoding []string
+	Close            bool
+	Header           map[string]string
+	Body             string
+}
+
+// comparedHeaders: 두 엔진이 같게 보내야 하는 헤더
+var comparedHeaders = []string{"Content-Type", "Content-Length", "Connection", "X-Custom", "Allow"}
+
+// exchange: raw 바이트를 그대로 보내고 methods 개수만큼 응답을 읽은 뒤, 서버가 연결을 닫았는지 확인합니다.
+func exchange(t *testing.T, addr, raw string, methods ...string) ([]wireResponse, bool) {
+	t.Helper()
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.WriteString(c, raw); err != nil {
+		t.Fatal(err)
+	}
+
+	br := bufio.NewReader(c)
+	var out []wireResponse
+	for _, method := range methods {
+		resp, err := http.ReadResponse(br, &http.Request{Method: method})
+		if err != nil {
+			t.Fatalf("%s: reading response: %v", addr, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: reading body: %v", addr, err)
+		}
+		wr := wireResponse{
+			Status:           resp.StatusCode,
+			Proto:            resp.Proto,
+			ContentLength:    resp.ContentLength,
+			TransferEncoding: resp.TransferEncoding,
+			Close:            resp.Close,
+			Header:           map[string]string{},
+			Body:             string(body),
+		}
+		for _, name := range comparedHeaders {
+			if v := resp.Header.Get(name); v != "" {
+				wr.Header[name] = v
+			}
+		}
+		out = append(out, wr)
+	}
+
+	// 연결이 닫혔는지: 짧게 읽어 EOF이면 닫힘, 시간 초과면 keep-alive 유지
+	c.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, err = br.ReadByte()
+	closed := err == io.EOF
+	return out, closed
+}
+
+func TestConformance(t *testing.T) {
+	rawAddr, stdAddr := startServers(t, 0)
+
+	tests := []struct {
+		name    string
+		raw     string
+		methods []string
+	}{
+		{"simple GET", "GET /echo?a=1&b=two HTTP/1.1\r\nHost: example.com\r\nX-Test: 1\r\n\r\n", []string{"GET"}},
+		{"HEAD", "HEAD /echo HTTP/1.1\r\nHost: h\r\n\r\n", []string{"HEAD"}},
+		{"HEAD with fixed length", "HEAD /length HTTP/1.1\r\nHost: h\r\n\r\n", []string{"HEAD"}},
+		{"bare LF line endings", "GET /echo HTTP/1.1\nHost: h\n\n", []string{"GET"}},
+		{"header whitespace and case", "GET /echo HTTP/1.1\r\nhost: h\r\nx-lower:   padded value \t\r\nX-Multi: a\r\nX-Multi: b\r\n\r\n", []string{"GET"}},
+		{"POST with Content-Length", "POST /echo HTTP/1.1\r\nHost: h\r\nContent-Type: text/plain\r\nContent-Length: 11\r\n\r\nhello world", []string{"POST"}},
+		{"POST chunked with extension and trailer", "POST /echo HTTP/1.1\r\nHost: h\r\nTransfer-Encoding: chunked\r\nTrailer: X-Checksum\r\n\r\n5;ext=1\r\nhello\r\n6\r\n world\r\n0\r\nX-Checksum: abc\r\n\r\n", []string{"POST"}},
+		{"chunked overrides Content-Length", "POST /echo HTTP/1.1\r\nHost: h\r\nContent-Length: 100\r\nTransfer-Encoding: chunked\r\n\r\n3\r\nabc\r\n0\r\n\r\n", []string{"POST"}},
+		{"duplicate equal Content-Length", "POST /echo HTTP/1.1\r\nHost: h\r\nContent-Length: 3\r\nContent-Length: 3\r\n\r\nabc", []string{"POST"}},
+		{"pipelined requests", "GET /echo?n=1 HTTP/1.1\r\nHost: h\r\n\r\nPOST /echo?n=2 HTTP/1.1\r\nHost: h\r\nContent-Length: 2\r\n\r\nhiGET /length HTTP/1.1\r\nHost: h\r\n\r\n", []string{"GET", "POST", "GET"}},
+		{"unread body is skipped for keep-alive", "POST /nocontent HTTP/1.1\r\nHost: h\r\nContent-Length: 4\r\n\r\nabcdGET /echo HTTP/1.1\r\nHost: h\r\n\r\n", []string{"POST", "GET"}},
+		{"HTTP/1.0 closes by default", "GET /echo HTTP/1.0\r\n\r\n", []string{"GET"}},
+		{"HTTP/1.0 keep-alive", "GET /length HTTP/1.0\r\nConnection: keep-alive\r\n\r\nGET /length HTTP/1.0\r\nConnection: keep-alive\r\n\r\n", []string{"GET", "GET"}},
+		{"HTTP/1.0 ignores Transfer-Encoding", "GET /echo HTTP/1.0\r\nTransfer-Encoding: gzip\r\n\r\n", []string{"GET"}},
+		{"Connection: close", "GET /echo HTTP/1.1\r\nHost: h\r\nConnection: close\r\n\r\n", []string{"GET"}},
+		{"absolute-form target", "GET http://example.org/echo?x=1 HTTP/1.1\r\nHost: other\r\n\r\n", []string{"GET"}},
+		{"large response is chunked", "GET /big HTTP/1.1\r\nHost: h\r\n\r\n", []string{"GET"}},
+		{"large response to HTTP/1.0 closes", "GET /big HTTP/1.0\r\n\r\n", []string{"GET"}},
+		{"flush switches to chunked", "GET /flush HTTP/1.1\r\nHost: h\r\n\r\n", []string{"GET"}},
+		{"204 has no body", "GET /nocontent HTTP/1.1\r\nHost: h\r\n\r\n", []string{"GET"}},
+		{"custom status and sniffed type", "GET /status HTTP/1.1\r\nHost: h\r\n\r\n", []string{"GET"}},
+		{"not found", "GET /missing HTTP/1.1\r\nHost: h\r\n\r\n", []string{"GET"}},
+		{"missing Host", "GET /echo HTTP/1.1\r\n\r\n", []string{"GET"}},
+		{"two Host headers", "GET /echo HTTP/1.1\r\nHost: a\r\nHost: b\r\n\r\n", []string{"GET"}},
+		{"malformed request line", "GARBAGE\r\n\r\n", []string{"GET"}},
+		{"malformed version", "GET / HTTX/1.1\r\nHost: h\r\n\r\n", []string{"GET"}},
+		{"unsupported version", "GET / HTTP/2.0\r\nHost: h\r\n\r\n", []string{"GET"}},
+		{"space before colon", "GET /echo HTTP/1.1\r\nHost: h\r\nX-Bad : v\r\n\r\n", []string{"GET"}},
+		{"unsupported transfer encoding", "POST /echo HTTP/1.1\r\nHost: h\r\nTransfer-Encoding: gzip\r\n\r\n", []string{"POST"}},
+		{"conflicting Content-Length", "POST /echo HTTP/1.1\r\nHost: h\r\nContent-Length: 3\r\nContent-Length: 4\r\n\r\nabcd", []string{"POST"}},
+		{"invalid Content-Length", "POST /echo HTTP/1.1\r\nHost: h\r\nContent-Length: -1\r\n\r\n", []string{"POST"}},
+		{"unknown expectation", "POST /echo HTTP/1.1\r\nHost: h\r\nExpect: magic\r\nContent-Length: 1\r\n\r\nx", []string{"POST"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			want, wantClosed := exchange(t, stdAddr, tt.raw, tt.methods...)
+			got, gotClosed := exchange(t, rawAddr, tt.raw, tt.methods...)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("responses differ\nrawhttp:  %+v\nnet/http: %+v", got, want)
+			}
+			if gotClosed != wantClosed {
+				t.Errorf("connection closed: rawhttp %v, net/http %v", gotClosed, wantClosed)
+			}
+		})
+	}
+}
+
+func TestHeaderTooLarge(t *testing.T) {
+	rawAddr, stdAddr := startServers(t, 1024)
+	raw := "GET /echo HTTP/1.1\r\nHost: h\r\nX-Big: " + strings.Repeat("x", 8000) + "\r\n\r\n"
+
+	want, _ := exchange(t, stdAddr, raw, "GET")
+	got, _ := exchange(t, rawAddr, raw, "GET")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("responses differ\nrawhttp:  %+v\nnet/http: %+v", got, want)
+	}
+	if got[0].Status != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("status = %d, want 431", got[0].Status)
+	}
+}
+
+// TestExpectContinue: 클라이언트가 헤더만 보내고 100 Continue를 받은 뒤에 본문을 보내는 흐름
+func TestExpectContinue(t *testing.T) {
+	rawAddr, stdAddr := startServers(t, 0)
+
+	run := func(addr string) (string, wireResponse) {
+		c, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		c.SetDeadline(time.Now().Add(5 * time.Second))
+		io.WriteString(c, "POST /echo HTTP/1.1\r\nHost: h\r\nExpect: 100-continue\r\nContent-Length: 5\r\n\r\n")
+
+		br := bufio.NewReader(c)
+		interim, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatalf("%s: reading interim response: %v", addr, err)
+		}
+		if blank, _ := br.ReadString('\n'); blank != "\r\n" {
+			t.Fatalf("%s: interim response has headers: %q", addr, blank)
+		}
+		io.WriteString(c, "hello")
+
+		resp, err := http.ReadResponse(br, nil)
+		if err != nil {
+			t.Fatalf("%s: reading response: %v", addr, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return interim, wireResponse{Status: resp.StatusCode, ContentLength: resp.ContentLength, Body: string(body)}
+	}
+
+	wantInterim, want := run(stdAddr)
+	gotInterim, got := run(rawAddr)
+	if gotInterim != wantInterim {
+		t.Errorf("interim response: rawhttp %q, net/http %q", gotInterim, wantInterim)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("responses differ\nrawhttp:  %+v\nnet/http: %+v", got, want)
+	}
+}
+
+// TestParseRequest: 요청 해석 결과를 net/http의 http.ReadRequest와 비교합니다.
+func TestParseRequest(t *testing.T) {
+	raws := []string{
+		"GET /a/b%20c?q=1 HTTP/1.1\r\nHost: example.com:8080\r\nAccept: */*\r\nAccept: text/html\r\n\r\n",
+		"POST /form HTTP/1.1\r\nHost: h\r\nContent-Type: application/x-www-form-urlencoded\r\nContent-Length: 7\r\n\r\nvar1=9&",
+		"OPTIONS * HTTP/1.1\r\nHost: h\r\n\r\n",
+		"GET http://proxy.example/x HTTP/1.1\r\nHost: ignored\r\n\r\n",
+		"GET /old HTTP/1.0\r\nConnection: Keep-Alive\r\n\r\n",
+	}
+	for _, raw := range raws {
+		want, err := http.ReadRequest(bufio.NewReader(strings.NewReader(raw)))
+		if err != nil {
+			t.Fatalf("net/http rejected %q: %v", raw, err)
+		}
+		got, _, err := readRequest(bufio.NewReader(strings.NewReader(raw)), DefaultMaxHeaderBytes)
+		if err != nil {
+			t.Fatalf("rawhttp rejected %q: %v", raw, err)
+		}
+
+		summary := func(r *http.Request) string {
+			keys := make([]string, 0, len(r.Header))
+			for k := range r.Header {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "%s %q %s host=%q url=%q close=%v len=%d\n", r.Method, r.RequestURI, r.Proto, r.Host, r.URL.String(), r.Close, r.ContentLength)
+			for _, k := range keys {
+				fmt.Fprintf(&sb, "%s: %q\n", k, r.Header[k])
+			}
+			body, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(&sb, "body=%q", body)
+			return sb.String()
+		}
+		if g, w := summary(got), summary(want); g != w {
+			t.Errorf("request %q parsed differently\nrawhttp:\n%s\nnet/http:\n%s", raw, g, w)
+		}
+	}
+}
@@ -0,0 +1,465 @@
+package rawhttp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// statusError: 요청을 해석할 수 없을 때 응답할 상태 코드와 이유
+type statusError struct {
+	code   int
+	text   string
+	public bool // 이유를 응답 본문에 포함해도 되는지 (요청 내용을 되돌려 보내지 않는 고정 문구)
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.code, http.StatusText(e.code), e.text)
+}
+
+func badRequest(format string, args ...any) error {
+	return &statusError{code: http.StatusBadRequest, text: fmt.Sprintf(format, args...)}
+}
+
+// publicBadRequest: 이유를 응답 본문에도 쓰는 400 오류 (net/http와 같은 문구)
+func publicBadRequest(text string) error {
+	return &statusError{code: http.StatusBadRequest, text: text, public: true}
+}
+
+// errHeaderTooLarge: 요청 줄과 헤더가 MaxHeaderBytes를 넘음 (431)
+var errHeaderTooLarge = &statusError{code: http.StatusRequestHeaderFieldsTooLarge, text: "request header too large"}
+
+// maxChunkLineLength: 청크 크기 줄(확장 포함)의 최대 길이
+const maxChunkLineLength = 4096
+
+// readLine: CRLF(또는 LF)로 끝나는 한 줄을 읽어 줄바꿈을 뗀 문자열로 반환합니다.
+// 읽은 바이트 수를 *remain에서 빼고, 모자라면 errHeaderTooLarge를 반환합니다.
+func readLine(br *bufio.Reader, remain *int) (string, error) {
+	var line []byte
+	for {
+		frag, err := br.ReadSlice('\n')
+		*remain -= len(frag)
+		if *remain < 0 {
+			return "", errHeaderTooLarge
+		}
+		line = append(line, frag...)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			if err == io.EOF && len(line) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return "", err
+		}
+	}
+	line = line[:len(line)-1] // '\n'
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line = line[:n-1]
+	}
+	return string(line), nil
+}
+
+// isTokenChar: RFC 9110 token 문자 (메서드, 헤더 이름)
+func isTokenChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
+
+func validToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isTokenChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// validHeaderValue: 헤더 값에 탭 이외의 제어 문자가 없는지 확인합니다.
+func validHeaderValue(v string) bool {
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if (c < ' ' && c != '\t') || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// parseVersion: "HTTP/1.1" 형식의 버전을 해석합니다.
+func parseVersion(proto string) (major, minor int, ok bool) {
+	if len(proto) != len("HTTP/1.1") || !strings.HasPrefix(proto, "HTTP/") || proto[6] != '.' {
+		return 0, 0, false
+	}
+	ma, mi := proto[5], proto[7]
+	if ma < '0' || ma > '9' || mi < '0' || mi > '9' {
+		return 0, 0, false
+	}
+	return int(ma - '0'), int(mi - '0'), true
+}
+
+// shouldClose: 응답 후 연결을 닫아야 하는지 (HTTP/1.0은 keep-alive를 명시해야 유지)
+func shouldClose(major, minor int, h http.Header) bool {
+	if major == 1 && minor == 0 {
+		return !headerHasToken(h, "Connection", "keep-alive")
+	}
+	return headerHasToken(h, "Connection", "close")
+}
+
+// headerHasToken: 쉼표로 구분된 헤더 값에 token이 있는지 확인합니다. (대소문자 무시)
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h[name] {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readRequest: 요청 줄, 헤더를 읽고 본문 길이(Content-Length 또는 chunked)를 결정합니다.
+// 본문은 읽지 않고 req.Body로 연결해 둡니다. 헤더 전체의 크기는 maxHeaderBytes로 제한됩니다.
+func readRequest(br *bufio.Reader, maxHeaderBytes int) (*http.Request, *body, error) {
+	remain := maxHeaderBytes
+
+	// 요청 줄: METHOD SP request-target SP HTTP-version (앞의 빈 줄은 무시, RFC 9112 2.2)
+	var line string
+	for {
+		var err error
+		if line, err = readLine(br, &remain); err != nil {
+			return nil, nil, err
+		}
+		if line != "" {
+			break
+		}
+	}
+	method, rest, ok1 := strings.Cut(line, " ")
+	target, proto, ok2 := strings.Cut(rest, " ")
+	if !ok1 || !ok2 {
+		return nil, nil, badRequest("malformed request line %q", line)
+	}
+	if !validToken(method) {
+		return nil, nil, badRequest("invalid method %q", method)
+	}
+	major, minor, ok := parseVersion(proto)
+	if !ok {
+		return nil, nil, badRequest("malformed HTTP version %q", proto)
+	}
+	if major != 1 {
+		return nil, nil, &statusError{code: http.StatusHTTPVersionNotSupported, text: "unsupported protocol version", public: true}
+	}
+
+	req := &http.Request{
+		Method:     method,
+		RequestURI: target,
+		Proto:      proto,
+		ProtoMajor: major,
+		ProtoMinor: minor,
+		Header:     make(http.Header),
+	}
+	var err error
+	if method == http.MethodConnect && !strings.HasPrefix(target, "/") {
+		req.URL = &url.URL{Host: target} // authority-form
+	} else if req.URL, err = url.ParseRequestURI(target); err != nil {
+		return nil, nil, badRequest("malformed request URI %q", target)
+	}
+
+	// 헤더 필드: name ":" OWS value OWS
+	for {
+		line, err := readLine(br, &remain)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, nil, err
+		}
+		if line == "" {
+			break
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, nil, badRequest("obsolete line folding is not supported")
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, nil, badRequest("malformed header line %q", line)
+		}
+		if !validToken(name) {
+			return nil, nil, publicBadRequest("invalid header name")
+		}
+		value = strings.Trim(value, " \t")
+		if !validHeaderValue(value) {
+			return nil, nil, publicBadRequest("invalid header value")
+		}
+		req.Header.Add(textproto.CanonicalMIMEHeaderKey(name), value)
+	}
+
+	// Host: HTTP/1.1에서는 필수이며 하나만 허용. 절대 형식 URL의 호스트가 우선합니다.
+	hosts := req.Header["Host"]
+	switch {
+	case len(hosts) > 1:
+		return nil, nil, badRequest("too many Host headers")
+	case len(hosts) == 0 && minor >= 1:
+		return nil, nil, publicBadRequest("missing required Host header")
+	}
+	req.Host = req.URL.Host
+	if req.Host == "" && len(hosts) == 1 {
+		req.Host = hosts[0]
+	}
+	if strings.ContainsAny(req.Host, " \t\r\n/\\") || !validHeaderValue(req.Host) {
+		return nil, nil, publicBadRequest("malformed Host header")
+	}
+	delete(req.Header, "Host")
+	req.Close = shouldClose(major, minor, req.Header)
+
+	b, err := readTransfer(br, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, b, nil
+}
+
+// readTransfer: Transfer-Encoding과 Content-Length로 본문 길이를 정합니다. (RFC 9112 6.3)
+// 요청 밀반입(request smuggling)을 막기 위해 chunked 하나만 허용하고, 서로 다른 Content-Length는 거부합니다.
+func readTransfer(br *bufio.Reader, req *http.Request) (*body, error) {
+	te, hasTE := req.Header["Transfer-Encoding"]
+	delete(req.Header, "Transfer-Encoding")
+	chunked := false
+	if hasTE && req.ProtoAtLeast(1, 1) { // HTTP/1.0의 Transfer-Encoding은 무시
+		if len(te) != 1 || !strings.EqualFold(te[0], "chunked") {
+			return nil, &statusError{code: http.StatusNotImplemented, text: "Unsupported transfer encoding", public: true}
+		}
+		chunked = true
+	}
+
+	lengths := req.Header["Content-Length"]
+	if len(lengths) > 1 {
+		first := strings.TrimSpace(lengths[0])
+		for _, v := range lengths[1:] {
+			if strings.TrimSpace(v) != first {
+				return nil, badRequest("multiple Content-Length headers %q", lengths)
+			}
+		}
+		req.Header["Content-Length"] = []string{first}
+		lengths = lengths[:1]
+	}
+	var n int64
+	if len(lengths) == 1 {
+		s := strings.TrimSpace(lengths[0])
+		var err error
+		n, err = strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 0 || s[0] == '+' {
+			return nil, badRequest("invalid Content-Length %q", lengths[0])
+		}
+	}
+
+	switch {
+	case chunked:
+		// Transfer-Encoding이 Content-Length보다 우선
+		delete(req.Header, "Content-Length")
+		req.TransferEncoding = []string{"chunked"}
+		req.ContentLength = -1
+		req.Trailer = declaredTrailers(req.Header)
+		delete(req.Header, "Trailer")
+		b := &body{src: &chunkedReader{br: br, req: req}}
+		req.Body = b
+		return b, nil
+	case n > 0:
+		req.ContentLength = n
+		b := &body{src: &lengthReader{r: br, n: n}}
+		req.Body = b
+		return b, nil
+	default:
+		req.Body = http.NoBody
+		return &body{sawEOF: true}, nil
+	}
+}
+
+// declaredTrailers: "Trailer" 헤더로 예고된 트레일러 이름 (값은 본문을 다 읽은 뒤 채워짐)
+func declaredTrailers(h http.Header) http.Header {
+	var trailer http.Header
+	for _, v := range h["Trailer"] {
+		for _, name := range strings.Split(v, ",") {
+			name = textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if trailer == nil {
+				trailer = make(http.Header)
+			}
+			trailer[name] = nil
+		}
+	}
+	return trailer
+}
+
+// body: 요청 본문. 끝까지 읽었는지 기록하여 keep-alive 연결에서 다음 요청 위치를 맞춥니다.
+type body struct {
+	src         io.Reader
+	sawEOF      bool
+	closed      bool
+	onFirstRead func() // "Expect: 100-continue" 요청에 대한 100 응답 전송
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	if b.closed {
+		return 0, http.ErrBodyReadAfterClose
+	}
+	if b.sawEOF {
+		return 0, io.EOF
+	}
+	if b.onFirstRead != nil {
+		b.onFirstRead()
+		b.onFirstRead = nil
+	}
+	n, err := b.src.Read(p)
+	if err == io.EOF {
+		b.sawEOF = true
+	}
+	return n, err
+}
+
+func (b *body) Close() error {
+	b.closed = true
+	return nil
+}
+
+// discard: 핸들러가 읽지 않은 본문을 최대 limit바이트까지 버립니다. 끝까지 버렸으면 true.
+func (b *body) discard(limit int64) bool {
+	if b.sawEOF {
+		return true
+	}
+	if b.onFirstRead != nil {
+		// 100 Continue를 보내지 않았으므로 클라이언트는 본문을 보내지 않았음: 연결을 닫아야 함
+		return false
+	}
+	if _, err := io.CopyN(io.Discard, b.src, limit+1); err == io.EOF {
+		b.sawEOF = true
+		return true
+	}
+	return false
+}
+
+// lengthReader: Content-Length만큼 읽는 리더. 연결이 먼저 끊기면 io.ErrUnexpectedEOF.
+type lengthReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *lengthReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if err == io.EOF && l.n > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	if err == nil && l.n == 0 {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// chunkedReader: chunked 전송 코딩을 해석하는 리더 (RFC 9112 7.1)
+//
+//	chunk-size [; ext] CRLF chunk-data CRLF ... 0 CRLF trailer-fields CRLF
+type chunkedReader struct {
+	br      *bufio.Reader
+	req     *http.Request // 트레일러를 채울 요청
+	n       int64         // 현재 청크에서 남은 바이트
+	inChunk bool
+	err     error
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	for c.err == nil && c.n == 0 {
+		c.err = c.beginChunk()
+	}
+	if c.err != nil {
+		return 0, c.err
+	}
+	if int64(len(p)) > c.n {
+		p = p[:c.n]
+	}
+	n, err := c.br.Read(p)
+	c.n -= int64(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		c.err = err
+	}
+	return n, err
+}
+
+// beginChunk: 이전 청크의 CRLF와 다음 청크의 크기 줄을 읽습니다. 마지막(0) 청크면 트레일러를 읽고 io.EOF.
+func (c *chunkedReader) beginChunk() error {
+	if c.inChunk {
+		remain := 2
+		if line, err := readLine(c.br, &remain); err != nil || line != "" {
+			return errors.New("rawhttp: malformed chunked encoding (missing CRLF after chunk data)")
+		}
+		c.inChunk = false
+	}
+
+	remain := maxChunkLineLength
+	line, err := readLine(c.br, &remain)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	sizeStr, _, _ := strings.Cut(line, ";") // 청크 확장은 무시
+	sizeStr = strings.TrimRight(sizeStr, " \t")
+	if sizeStr == "" || len(sizeStr) > 16 {
+		return fmt.Errorf("rawhttp: invalid chunk size %q", sizeStr)
+	}
+	size, err := strconv.ParseUint(sizeStr, 16, 64)
+	if err != nil || size > 1<<62 {
+		return fmt.Errorf("rawhttp: invalid chunk size %q", sizeStr)
+	}
+	if size > 0 {
+		c.n, c.inChunk = int64(size), true
+		return nil
+	}
+
+	// 마지막 청크: 트레일러 필드
+	remain = maxChunkLineLength * 4
+	for {
+		line, err := readLine(c.br, &remain)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		if line == "" {
+			return io.EOF
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || !validToken(name) {
+			return fmt.Errorf("rawhttp: malformed trailer line %q", line)
+		}
+		if c.req.Trailer == nil {
+			c.req.Trailer = make(http.Header)
+		}
+		c.req.Trailer.Add(textproto.CanonicalMIMEHeaderKey(name), strings.Trim(value, " \t"))
+	}
+}
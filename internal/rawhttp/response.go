@@ -0,0 +1,338 @@
+package rawhttp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bufferSize: 헤더를 보내기 전에 모아 두는 본문 크기.
+// 핸들러가 이 안에서 끝나면 Content-Length를 붙이고, 넘으면 chunked로 보냅니다. (net/http와 같은 방식)
+const bufferSize = 4096
+
+// maxDiscard: 핸들러가 읽지 않은 요청 본문을 keep-alive를 위해 대신 읽어 버리는 최대 크기
+const maxDiscard = 256 << 10
+
+// response: http.ResponseWriter 구현. 상태 줄과 헤더를 직접 직렬화합니다.
+type response struct {
+	conn *conn
+	req  *http.Request
+	body *body
+
+	handlerHeader http.Header // 핸들러가 수정하는 헤더
+	header        http.Header // WriteHeader 시점의 복사본 (이후 수정은 반영하지 않음)
+	status        int
+	wroteHeader   bool // 핸들러가 상태 코드를 정함
+	headerSent    bool // 상태 줄과 헤더를 연결에 씀
+
+	buf           []byte // 헤더 전송 전까지 모아 둔 본문
+	written       int64  // 핸들러가 쓴 본문 바이트 (HEAD 포함)
+	contentLength int64  // 핸들러가 지정한 Content-Length (-1이면 없음)
+	chunked       bool
+	closeAfter    bool // 응답 후 연결 종료
+	handlerDone   bool
+	hijacked      bool
+	fullDuplex    bool
+	continueSent  bool
+}
+
+func newResponse(c *conn, req *http.Request, b *body) *response {
+	return &response{conn: c, req: req, body: b, handlerHeader: make(http.Header), contentLength: -1}
+}
+
+// bodyAllowed: 본문을 가질 수 있는 상태 코드인지 (1xx, 204, 304는 본문 없음)
+func bodyAllowed(status int) bool {
+	return !(status >= 100 && status <= 199) && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+func (w *response) Header() http.Header {
+	return w.handlerHeader
+}
+
+func (w *response) WriteHeader(code int) {
+	if w.hijacked {
+		log.Printf("rawhttp: response.WriteHeader on hijacked connection")
+		return
+	}
+	if w.wroteHeader {
+		log.Printf("rawhttp: superfluous response.WriteHeader call (status %d)", code)
+		return
+	}
+	if code < 100 || code > 999 {
+		panic(fmt.Sprintf("invalid WriteHeader code %v", code))
+	}
+
+	// 1xx(101 제외) 정보 응답은 바로 보내고, 최종 응답을 계속 기다림
+	if code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols {
+		if code == http.StatusContinue {
+			w.continueSent = true
+		}
+		w.writeStatusAndHeader(code, w.handlerHeader)
+		w.conn.bw.Flush()
+		return
+	}
+
+	w.wroteHeader, w.status = true, code
+	w.header = w.handlerHeader.Clone()
+	if cl := w.header.Get("Content-Length"); cl != "" {
+		if n, err := strconv.ParseInt(cl, 10, 64); err == nil && n >= 0 {
+			w.contentLength = n
+		} else {
+			log.Printf("rawhttp: invalid Content-Length %q", cl)
+			w.header.Del("Content-Length")
+		}
+	}
+}
+
+func (w *response) Write(p []byte) (int, error) {
+	if w.hijacked {
+		return 0, http.ErrHijacked
+	}
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if !bodyAllowed(w.status) {
+		return 0, http.ErrBodyNotAllowed
+	}
+	if w.contentLength >= 0 && w.written+int64(len(p)) > w.contentLength {
+		return 0, http.ErrContentLength
+	}
+	w.written += int64(len(p))
+
+	if !w.headerSent {
+		if len(w.buf)+len(p) <= bufferSize {
+			w.buf = append(w.buf, p...)
+			return len(p), nil
+		}
+		if err := w.sendHeader(); err != nil {
+			return 0, err
+		}
+	}
+	if err := w.writeBody(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeBody: 본문 조각을 씁니다. chunked이면 "크기 CRLF 데이터 CRLF"로 감쌉니다.
+func (w *response) writeBody(p []byte) error {
+	if len(p) == 0 || w.req.Method == http.MethodHead {
+		return nil // HEAD는 본문을 보내지 않음 (Content-Type 추측과 길이 계산에만 사용)
+	}
+	bw := w.conn.bw
+	if w.chunked {
+		fmt.Fprintf(bw, "%x\r\n", len(p))
+	}
+	if _, err := bw.Write(p); err != nil {
+		return err
+	}
+	if w.chunked {
+		_, err := bw.WriteString("\r\n")
+		return err
+	}
+	return nil
+}
+
+// sendHeader: 본문 길이 전달 방식을 정하고 상태 줄과 헤더를 쓴 뒤, 모아 둔 본문을 씁니다.
+func (w *response) sendHeader() error {
+	if w.headerSent {
+		return nil
+	}
+	w.headerSent = true
+	h := w.header
+
+	if h.Get("Date") == "" {
+		h.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+	hasTE := h.Get("Transfer-Encoding") != ""
+	if bodyAllowed(w.status) && h.Get("Content-Type") == "" && !hasTE && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	// 읽지 않은 요청 본문: 응답이 끝났으면 대신 읽어 버리고, 너무 크면 연결을 닫음
+	if !w.body.sawEOF && !(w.fullDuplex && !w.handlerDone) {
+		if !w.handlerDone || !w.body.discard(maxDiscard) {
+			w.closeAfter = true
+		}
+	}
+
+	// 본문 길이 전달 방식 (RFC 9112 6)
+	isHead := w.req.Method == http.MethodHead
+	switch {
+	case !bodyAllowed(w.status):
+		h.Del("Transfer-Encoding")
+		if w.status != http.StatusNotModified {
+			h.Del("Content-Length")
+		}
+	case hasTE:
+		// 핸들러가 직접 Transfer-Encoding을 지정: 길이를 알 수 없으므로 연결 종료로 본문 끝을 알림
+		h.Del("Content-Length")
+		w.closeAfter = true
+	case w.contentLength >= 0:
+		// 핸들러가 지정한 Content-Length 사용
+	case w.handlerDone && (!isHead || w.written > 0):
+		h.Set("Content-Length", strconv.FormatInt(w.written, 10))
+	case isHead:
+		// HEAD: 본문이 없으므로 길이 정보 없이 보냄
+	case w.req.ProtoAtLeast(1, 1):
+		w.chunked = true
+		h.Set("Transfer-Encoding", "chunked")
+	default:
+		// HTTP/1.0 클라이언트는 chunked를 모르므로 연결 종료로 본문 끝을 알림
+		w.closeAfter = true
+	}
+
+	if headerHasToken(h, "Connection", "close") {
+		w.closeAfter = true
+	}
+	if w.req.Close || w.conn.server.shuttingDown.Load() {
+		w.closeAfter = true
+	}
+	if w.closeAfter {
+		h.Del("Connection")
+		if w.req.ProtoAtLeast(1, 1) {
+			h.Set("Connection", "close") // HTTP/1.0은 keep-alive가 없으면 원래 닫으므로 생략
+		}
+	} else if !w.req.ProtoAtLeast(1, 1) {
+		h.Set("Connection", "keep-alive")
+	}
+
+	if err := w.writeStatusAndHeader(w.status, h); err != nil {
+		return err
+	}
+	buf := w.buf
+	w.buf = nil
+	return w.writeBody(buf)
+}
+
+// writeStatusAndHeader: "HTTP/1.1 200 OK" 형식의 상태 줄과 헤더 필드(이름순)를 씁니다.
+func (w *response) writeStatusAndHeader(code int, h http.Header) error {
+	bw := w.conn.bw
+	text := http.StatusText(code)
+	if text == "" {
+		text = "status code " + strconv.Itoa(code)
+	}
+	proto := "HTTP/1.1"
+	if !w.req.ProtoAtLeast(1, 1) {
+		proto = "HTTP/1.0" // HTTP/1.0 클라이언트에는 같은 버전으로 응답
+	}
+	fmt.Fprintf(bw, "%s %d %s\r\n", proto, code, text)
+	// Header.Write는 이름순으로 정렬하고 값의 줄바꿈을 공백으로 바꿔 헤더 분할을 막음
+	if err := h.Write(bw); err != nil {
+		return err
+	}
+	_, err := bw.WriteString("\r\n")
+	return err
+}
+
+// finish: 핸들러가 끝난 뒤 남은 헤더/본문과 마지막 청크를 보냅니다.
+func (w *response) finish() error {
+	w.handlerDone = true
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if err := w.sendHeader(); err != nil {
+		return err
+	}
+	if w.chunked {
+		if _, err := w.conn.bw.WriteString("0\r\n\r\n"); err != nil {
+			return err
+		}
+	}
+	if w.contentLength >= 0 && w.written != w.contentLength && w.req.Method != http.MethodHead && bodyAllowed(w.status) {
+		// 선언한 길이보다 적게 씀: 클라이언트가 다음 응답을 잘못 읽지 않도록 연결을 닫음
+		w.closeAfter = true
+	}
+	return w.conn.bw.Flush()
+}
+
+// sendContinue: "Expect: 100-continue" 요청의 본문을 처음 읽을 때 100 Continue를 보냅니다.
+func (w *response) sendContinue() {
+	if w.continueSent || w.headerSent {
+		return
+	}
+	w.continueSent = true
+	w.conn.bw.WriteString("HTTP/1.1 100 Continue\r\n\r\n")
+	w.conn.bw.Flush()
+}
+
+// Flush: http.Flusher
+func (w *response) Flush() {
+	w.FlushError()
+}
+
+// FlushError: 지금까지 쓴 헤더와 본문을 바로 전송합니다. (http.ResponseController.Flush)
+func (w *response) FlushError() error {
+	if w.hijacked {
+		return http.ErrHijacked
+	}
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if err := w.sendHeader(); err != nil {
+		return err
+	}
+	return w.conn.bw.Flush()
+}
+
+// Hijack: http.Hijacker. 연결을 핸들러에 넘깁니다. (WebSocket 등)
+func (w *response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.hijacked {
+		return nil, nil, http.ErrHijacked
+	}
+	if w.headerSent {
+		if err := w.conn.bw.Flush(); err != nil {
+			return nil, nil, err
+		}
+	}
+	w.hijacked = true
+	w.conn.hijacked = true
+	return w.conn.rwc, bufio.NewReadWriter(w.conn.br, w.conn.bw), nil
+}
+
+// SetReadDeadline: http.ResponseController.SetReadDeadline
+func (w *response) SetReadDeadline(t time.Time) error {
+	return w.conn.rwc.SetReadDeadline(t)
+}
+
+// SetWriteDeadline: http.ResponseController.SetWriteDeadline
+func (w *response) SetWriteDeadline(t time.Time) error {
+	return w.conn.rwc.SetWriteDeadline(t)
+}
+
+// EnableFullDuplex: 응답을 쓰는 동안에도 요청 본문을 계속 읽을 수 있게 합니다.
+func (w *response) EnableFullDuplex() error {
+	w.fullDuplex = true
+	return nil
+}
+
+// writeError: 요청을 해석하지 못했을 때 net/http와 같은 형식의 오류 응답을 씁니다.
+func writeError(bw *bufio.Writer, err error) {
+	const errorHeaders = "\r\nContent-Type: text/plain; charset=utf-8\r\nConnection: close\r\n\r\n"
+
+	var se *statusError
+	if !errors.As(err, &se) {
+		se = &statusError{code: http.StatusBadRequest}
+	}
+	status := fmt.Sprintf("%d %s", se.code, http.StatusText(se.code))
+	switch {
+	case !se.public:
+		// 요청 내용이 담긴 이유는 응답에 그대로 되돌려 보내지 않음
+		bw.WriteString("HTTP/1.1 " + status + errorHeaders + status)
+	case se.code == http.StatusNotImplemented:
+		bw.WriteString("HTTP/1.1 " + status + errorHeaders + se.text)
+	default:
+		text := strings.ReplaceAll(se.text, "\r\n", " ")
+		bw.WriteString("HTTP/1.1 " + status + ": " + text + errorHeaders + status + ": " + text)
+	}
+	bw.Flush()
+}
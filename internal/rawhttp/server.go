@@ -0,0 +1,360 @@
+// Package rawhttp는 net/http의 서버를 쓰지 않고 net.Listener 위에서 HTTP/1.1을 직접 구현한 서버 엔진입니다.
+// 요청 줄과 헤더 해석, Content-Length/chunked 본문, keep-alive와 파이프라이닝, 100-continue를
+// 수업용으로 눈에 보이게 구현하며, 핸들러는 기존 http.Handler(myHttpHandler 등)를 그대로 사용합니다.
+package rawhttp
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxHeaderBytes: MaxHeaderBytes가 0일 때의 헤더 크기 제한 (net/http와 같음)
+const DefaultMaxHeaderBytes = 1 << 20
+
+// rstAvoidanceDelay: 연결을 닫기 전 클라이언트가 응답을 읽을 시간을 주는 대기 (RST로 응답이 유실되는 것 방지)
+const rstAvoidanceDelay = 500 * time.Millisecond
+
+// Server: net/http.Server와 같은 이름의 설정 필드를 가진 HTTP/1.1 서버
+type Server struct {
+	Addr              string
+	Handler           http.Handler
+	TLSConfig         *tls.Config // 설정되면 ListenAndServe가 TLS로 연결을 받음
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+
+	shuttingDown atomic.Bool
+
+	mu         sync.Mutex
+	listeners  map[net.Listener]struct{}
+	conns      map[*conn]struct{}
+	onShutdown []func()
+}
+
+// ListenAndServe: Addr에서 연결을 받습니다. TLSConfig가 있으면 HTTPS로 동작합니다.
+func (s *Server) ListenAndServe() error {
+	if s.shuttingDown.Load() {
+		return http.ErrServerClosed
+	}
+	l, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	if s.TLSConfig != nil {
+		return s.ServeTLS(l)
+	}
+	return s.Serve(l)
+}
+
+// ServeTLS: l에서 받은 연결을 TLSConfig로 감싸 HTTPS로 처리합니다.
+func (s *Server) ServeTLS(l net.Listener) error {
+	if s.TLSConfig == nil {
+		return errors.New("rawhttp: ServeTLS requires TLSConfig")
+	}
+	cfg := s.TLSConfig.Clone()
+	cfg.NextProtos = []string{"http/1.1"} // HTTP/2는 지원하지 않음
+	return s.Serve(tls.NewListener(l, cfg))
+}
+
+// Serve: l에서 연결을 받아 각각 고루틴에서 처리합니다. Shutdown/Close 후에는 http.ErrServerClosed를 반환합니다.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.listeners == nil {
+		s.listeners = make(map[net.Listener]struct{})
+		s.conns = make(map[*conn]struct{})
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, l)
+		s.mu.Unlock()
+		l.Close()
+	}()
+
+	var delay time.Duration
+	for {
+		rwc, err := l.Accept()
+		if err != nil {
+			if s.shuttingDown.Load() {
+				return http.ErrServerClosed
+			}
+			// 일시적 오류(파일 디스크립터 부족 등)는 점점 길게 기다렸다가 다시 시도
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() || errors.Is(err, os.ErrDeadlineExceeded) {
+				delay = min(max(2*delay, 5*time.Millisecond), time.Second)
+				log.Printf("rawhttp: accept error: %v; retrying in %v", err, delay)
+				time.Sleep(delay)
+				continue
+			}
+			return err
+		}
+		delay = 0
+
+		c := &conn{server: s, rwc: rwc}
+		if !s.trackConn(c, true) {
+			rwc.Close()
+			continue
+		}
+		go c.serve()
+	}
+}
+
+func (s *Server) trackConn(c *conn, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if add {
+		if s.shuttingDown.Load() {
+			return false
+		}
+		s.conns[c] = struct{}{}
+	} else {
+		delete(s.conns, c)
+	}
+	return true
+}
+
+// RegisterOnShutdown: Shutdown이 시작될 때 별도 고루틴에서 실행할 함수를 등록합니다.
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onShutdown = append(s.onShutdown, f)
+}
+
+// Shutdown: 새 연결을 받지 않고, 유휴 연결을 닫으며, 처리 중인 요청이 끝날 때까지 기다립니다.
+// ctx가 먼저 끝나면 ctx.Err()를 반환합니다. (남은 연결은 Close로 닫아야 함)
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.shuttingDown.Store(true)
+
+	s.mu.Lock()
+	for l := range s.listeners {
+		l.Close()
+	}
+	for _, f := range s.onShutdown {
+		go f()
+	}
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if s.closeIdleConns() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// closeIdleConns: 요청을 기다리는 연결을 닫고, 남은 연결이 없으면 true를 반환합니다.
+func (s *Server) closeIdleConns() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		if c.idle.Load() {
+			c.rwc.Close()
+		}
+	}
+	return len(s.conns) == 0
+}
+
+// Close: 모든 리스너와 연결을 즉시 닫습니다.
+func (s *Server) Close() error {
+	s.shuttingDown.Store(true)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for l := range s.listeners {
+		l.Close()
+	}
+	for c := range s.conns {
+		c.rwc.Close()
+	}
+	return nil
+}
+
+// conn: 클라이언트 연결 하나 (keep-alive로 여러 요청을 차례로 처리)
+type conn struct {
+	server   *Server
+	rwc      net.Conn
+	br       *bufio.Reader
+	bw       *bufio.Writer
+	idle     atomic.Bool // 다음 요청을 기다리는 중 (Shutdown 시 바로 닫아도 됨)
+	hijacked bool
+}
+
+func (c *conn) serve() {
+	s := c.server
+	defer func() {
+		s.trackConn(c, false)
+		if !c.hijacked {
+			c.rwc.Close()
+		}
+	}()
+
+	var tlsState *tls.ConnectionState
+	if tlsConn, ok := c.rwc.(*tls.Conn); ok {
+		c.setReadDeadline(s.headerTimeout())
+		c.rwc.SetWriteDeadline(deadline(s.headerTimeout()))
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		state := tlsConn.ConnectionState()
+		tlsState = &state
+	}
+
+	c.br = bufio.NewReader(c.rwc)
+	c.bw = bufio.NewWriterSize(c.rwc, bufferSize)
+
+	for first := true; ; first = false {
+		// 다음 요청의 첫 바이트를 기다리는 동안은 유휴 상태 (IdleTimeout)
+		if !first {
+			c.idle.Store(true)
+			if s.shuttingDown.Load() {
+				return
+			}
+			c.setReadDeadline(s.idleTimeout())
+			if _, err := c.br.Peek(1); err != nil {
+				return
+			}
+			c.idle.Store(false)
+		}
+
+		c.setReadDeadline(s.headerTimeout())
+		req, b, err := readRequest(c.br, s.maxHeaderBytes())
+		if err != nil {
+			var se *statusError
+			if errors.As(err, &se) {
+				c.rwc.SetWriteDeadline(deadline(time.Second))
+				writeError(c.bw, err)
+				c.closeWriteAndWait()
+			}
+			return // EOF, 시간 초과 등 네트워크 오류는 응답 없이 닫음
+		}
+
+		// 본문은 ReadTimeout(요청 시작 기준), 응답은 WriteTimeout까지
+		if s.ReadTimeout > 0 {
+			c.setReadDeadline(s.ReadTimeout)
+		} else {
+			c.rwc.SetReadDeadline(time.Time{})
+		}
+		c.rwc.SetWriteDeadline(deadline(s.WriteTimeout))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = context.WithValue(ctx, http.LocalAddrContextKey, c.rwc.LocalAddr())
+		req = req.WithContext(ctx)
+		req.RemoteAddr = c.rwc.RemoteAddr().String()
+		req.TLS = tlsState
+
+		w := newResponse(c, req, b)
+		if expect := req.Header.Get("Expect"); expect != "" {
+			if !headerHasToken(req.Header, "Expect", "100-continue") {
+				w.WriteHeader(http.StatusExpectationFailed)
+				w.closeAfter = true
+				w.finish()
+				cancel()
+				c.closeWriteAndWait()
+				return
+			}
+			if req.ProtoAtLeast(1, 1) && req.ContentLength != 0 {
+				b.onFirstRead = w.sendContinue
+			}
+		}
+
+		ok := c.runHandler(w, req)
+		cancel()
+		if c.hijacked {
+			return
+		}
+		if !ok {
+			return // 핸들러 패닉: 응답이 불완전할 수 있으므로 연결을 닫음
+		}
+		if err := w.finish(); err != nil {
+			return
+		}
+		if w.closeAfter {
+			c.closeWriteAndWait()
+			return
+		}
+	}
+}
+
+// runHandler: 핸들러를 실행하고, 패닉이 나면 기록한 뒤 false를 반환합니다.
+func (c *conn) runHandler(w *response, req *http.Request) (ok bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			if err != http.ErrAbortHandler {
+				buf := make([]byte, 64<<10)
+				buf = buf[:runtime.Stack(buf, false)]
+				log.Printf("rawhttp: panic serving %v: %v\n%s", c.rwc.RemoteAddr(), err, buf)
+			}
+			ok = false
+		}
+	}()
+	handler := c.server.Handler
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+	handler.ServeHTTP(w, req)
+	return true
+}
+
+// closeWriteAndWait: 쓰기 방향만 닫고 잠시 기다려, 클라이언트가 응답을 다 읽기 전에 RST가 가지 않게 합니다.
+func (c *conn) closeWriteAndWait() {
+	c.bw.Flush()
+	if cw, ok := c.rwc.(interface{ CloseWrite() error }); ok {
+		cw.CloseWrite()
+	}
+	c.rwc.SetReadDeadline(time.Now().Add(rstAvoidanceDelay))
+	io.Copy(io.Discard, c.rwc)
+}
+
+func (c *conn) setReadDeadline(d time.Duration) {
+	c.rwc.SetReadDeadline(deadline(d))
+}
+
+// deadline: d 후의 시각 (0이면 기한 없음)
+func deadline(d time.Duration) time.Time {
+	if d <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(d)
+}
+
+func (s *Server) headerTimeout() time.Duration {
+	if s.ReadHeaderTimeout > 0 {
+		return s.ReadHeaderTimeout
+	}
+	return s.ReadTimeout
+}
+
+func (s *Server) idleTimeout() time.Duration {
+	if s.IdleTimeout > 0 {
+		return s.IdleTimeout
+	}
+	return s.ReadTimeout
+}
+
+// maxHeaderBytes: 요청 줄과 헤더의 크기 제한 (net/http처럼 4096바이트의 여유를 둠)
+func (s *Server) maxHeaderBytes() int {
+	n := s.MaxHeaderBytes
+	if n <= 0 {
+		n = DefaultMaxHeaderBytes
+	}
+	return n + 4096
+}
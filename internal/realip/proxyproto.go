@@ -0,0 +1,206 @@
+package realip
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProxyHeaderTimeout: PROXY 프로토콜 헤더를 기다리는 최대 시간
+const DefaultProxyHeaderTimeout = 5 * time.Second
+
+// PROXY 프로토콜 서명 (https://www.haproxy.org/download/2.9/doc/proxy-protocol.txt)
+var (
+	proxyV1Sig = []byte("PROXY ")
+	proxyV2Sig = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// proxyV1MaxLen: v1 헤더 한 줄의 최대 길이 (CRLF 포함)
+const proxyV1MaxLen = 107
+
+// ProxyListener: 신뢰하는 프록시에서 온 연결의 PROXY 프로토콜 헤더를 읽어 RemoteAddr을 원래 클라이언트로 바꾸는 리스너.
+// 헤더가 없는 연결(예: 로드 밸런서의 상태 검사)은 그대로 받고, 신뢰하지 않는 상대가 보낸 헤더는 해석하지 않습니다.
+// TLS를 쓰는 경우 PROXY 헤더는 TLS보다 앞에 오므로 tls.NewListener 안쪽에 둡니다.
+type ProxyListener struct {
+	net.Listener
+	Resolver      *Resolver     // 헤더를 받아들일 프록시 목록
+	HeaderTimeout time.Duration // 헤더를 기다리는 최대 시간 (0이면 DefaultProxyHeaderTimeout)
+}
+
+// Accept: 연결을 받습니다. 헤더는 연결을 처리하는 고루틴에서 처음 읽거나 주소를 물을 때 읽으므로 Accept는 막히지 않습니다.
+func (l *ProxyListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := l.Resolver.trustedPeer(c.RemoteAddr().String()); !ok {
+		return c, nil
+	}
+	timeout := l.HeaderTimeout
+	if timeout <= 0 {
+		timeout = DefaultProxyHeaderTimeout
+	}
+	return &proxyConn{Conn: c, r: bufio.NewReader(c), timeout: timeout}, nil
+}
+
+// proxyConn: PROXY 헤더를 한 번 읽고, 그 뒤의 바이트는 그대로 전달하는 연결
+type proxyConn struct {
+	net.Conn
+	r       *bufio.Reader
+	timeout time.Duration
+
+	once   sync.Once
+	remote net.Addr
+	local  net.Addr
+	err    error
+}
+
+// readHeader: 헤더가 있으면 해석합니다. 서명이 없으면 헤더 없는 연결로 취급합니다.
+func (c *proxyConn) readHeader() {
+	c.once.Do(func() {
+		c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+		defer c.Conn.SetReadDeadline(time.Time{})
+
+		var src, dst net.Addr
+		if sig, _ := c.r.Peek(len(proxyV2Sig)); bytes.Equal(sig, proxyV2Sig) {
+			src, dst, c.err = readProxyV2(c.r)
+		} else if sig, _ := c.r.Peek(len(proxyV1Sig)); bytes.Equal(sig, proxyV1Sig) {
+			src, dst, c.err = readProxyV1(c.r)
+		}
+		if c.err != nil {
+			c.err = fmt.Errorf("PROXY protocol header from %s: %w", c.Conn.RemoteAddr(), c.err)
+			return
+		}
+		c.remote, c.local = src, dst
+	})
+}
+
+func (c *proxyConn) Read(p []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(p)
+}
+
+// RemoteAddr: 헤더에 적힌 원래 클라이언트 주소 (헤더가 없거나 LOCAL/UNKNOWN이면 프록시 주소)
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr: 헤더에 적힌 원래 목적지 주소
+func (c *proxyConn) LocalAddr() net.Addr {
+	c.readHeader()
+	if c.local != nil {
+		return c.local
+	}
+	return c.Conn.LocalAddr()
+}
+
+// readProxyV1: "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n" 형식의 텍스트 헤더
+func readProxyV1(r *bufio.Reader) (src, dst net.Addr, err error) {
+	var line []byte
+	for len(line) < proxyV1MaxLen {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, errors.New("v1 header is not terminated by CRLF within 107 bytes")
+	}
+	fields := strings.Fields(string(line[:len(line)-2]))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil, nil // 프록시가 원래 주소를 모름: 연결 상대 주소 사용
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, nil, fmt.Errorf("malformed v1 header %q", strings.TrimSpace(string(line)))
+	}
+	srcAddr, err := parseV1Addr(fields[2], fields[4])
+	if err != nil {
+		return nil, nil, err
+	}
+	dstAddr, err := parseV1Addr(fields[3], fields[5])
+	if err != nil {
+		return nil, nil, err
+	}
+	if srcAddr.Addr().Is4() != (fields[1] == "TCP4") {
+		return nil, nil, fmt.Errorf("v1 address %s does not match protocol %s", fields[2], fields[1])
+	}
+	return net.TCPAddrFromAddrPort(srcAddr), net.TCPAddrFromAddrPort(dstAddr), nil
+}
+
+// parseV1Addr: v1 헤더의 IP와 포트 필드
+func parseV1Addr(ip, port string) (netip.AddrPort, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("bad v1 address %q", ip)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("bad v1 port %q", port)
+	}
+	return netip.AddrPortFrom(addr, uint16(p)), nil
+}
+
+// readProxyV2: 바이너리 헤더 (서명 12바이트, 버전/명령, 주소 종류/프로토콜, 길이 2바이트, 주소와 TLV)
+func readProxyV2(r *bufio.Reader) (src, dst net.Addr, err error) {
+	var hdr [16]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, nil, err
+	}
+	verCmd, famProto := hdr[12], hdr[13]
+	length := int(binary.BigEndian.Uint16(hdr[14:16]))
+	if verCmd>>4 != 2 {
+		return nil, nil, fmt.Errorf("unsupported v2 version %d", verCmd>>4)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, nil, err
+	}
+
+	switch verCmd & 0x0f {
+	case 0x0: // LOCAL: 프록시 자신의 연결 (상태 검사 등)
+		return nil, nil, nil
+	case 0x1: // PROXY
+	default:
+		return nil, nil, fmt.Errorf("unsupported v2 command %#x", verCmd&0x0f)
+	}
+
+	var size int
+	switch famProto {
+	case 0x11: // TCP over IPv4
+		size = 4
+	case 0x21: // TCP over IPv6
+		size = 16
+	default:
+		return nil, nil, nil // UNSPEC, UDP, UNIX 소켓: 주소를 쓰지 않고 연결 상대 주소 사용
+	}
+	if len(body) < 2*size+4 {
+		return nil, nil, fmt.Errorf("v2 address block too short (%d bytes)", len(body))
+	}
+	srcIP, _ := netip.AddrFromSlice(body[:size])
+	dstIP, _ := netip.AddrFromSlice(body[size : 2*size])
+	srcPort := binary.BigEndian.Uint16(body[2*size:])
+	dstPort := binary.BigEndian.Uint16(body[2*size+2:])
+	// 나머지는 TLV(ALPN, SNI 등)이며 사용하지 않음
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(srcIP, srcPort)),
+		net.TCPAddrFromAddrPort(netip.AddrPortFrom(dstIP, dstPort)), nil
+}
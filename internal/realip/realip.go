@@ -0,0 +1,214 @@
+// Package realip는 로드 밸런서나 리버스 프록시 뒤에서 실제 클라이언트 IP를 찾습니다.
+// 신뢰하는 프록시(CIDR 목록)에서 온 요청에 한해 RFC 7239 Forwarded, X-Forwarded-For, X-Real-IP 헤더를 해석하고,
+// 리스너에서는 HAProxy PROXY 프로토콜(v1/v2) 헤더를 받을 수 있습니다. (proxyproto.go)
+//
+// Middleware는 r.RemoteAddr을 찾은 클라이언트 주소로 바꾸므로, 접근 로그·속도 제한·계산 기록처럼
+// RemoteAddr을 읽는 코드는 수정 없이 실제 클라이언트를 보게 됩니다. 원래 연결 상대(프록시) 주소는 Peer로 얻습니다.
+package realip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Resolver: 신뢰하는 프록시 목록으로 요청의 실제 클라이언트 주소를 찾는 해석기
+type Resolver struct {
+	trusted   []netip.Prefix
+	trustUnix bool // Unix 소켓으로 연결한 상대(같은 호스트의 프록시)를 신뢰
+}
+
+// ParseTrusted: 쉼표로 구분한 CIDR 또는 IP 목록(예: "10.0.0.0/8, 192.168.1.10")으로 Resolver를 만듭니다.
+// "unix"는 Unix 소켓 리스너로 들어온 연결을 뜻합니다. (소켓 파일 권한으로 접근이 제한된 로컬 프록시)
+func ParseTrusted(list string) (*Resolver, error) {
+	res := &Resolver{}
+	for _, s := range strings.Split(list, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if s == "unix" {
+			res.trustUnix = true
+			continue
+		}
+		if strings.Contains(s, "/") {
+			p, err := netip.ParsePrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy %q: %w", s, err)
+			}
+			res.trusted = append(res.trusted, p.Masked())
+			continue
+		}
+		ip, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: not an IP address or CIDR", s)
+		}
+		ip = ip.Unmap()
+		res.trusted = append(res.trusted, netip.PrefixFrom(ip, ip.BitLen()))
+	}
+	if len(res.trusted) == 0 && !res.trustUnix {
+		return nil, fmt.Errorf("trusted proxy list %q is empty", list)
+	}
+	return res, nil
+}
+
+// Trusted: ip가 신뢰하는 프록시 주소인지
+func (res *Resolver) Trusted(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, p := range res.trusted {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// trustedPeer: 연결 상대 주소(r.RemoteAddr 또는 net.Conn.RemoteAddr)가 신뢰하는 프록시인지.
+// TCP 연결의 주소는 항상 IP:포트이므로, IP가 아닌 주소("@" 등)는 Unix 소켓 연결로 봅니다.
+// Unix 소켓 상대는 IP가 없으므로 ip는 유효하지 않은 값입니다.
+func (res *Resolver) trustedPeer(addr string) (ip netip.Addr, ok bool) {
+	ip, isIP := addrIP(addr)
+	if !isIP {
+		return netip.Addr{}, res.trustUnix
+	}
+	return ip, res.Trusted(ip)
+}
+
+// hop: 전달 헤더에 적힌 주소 하나 (포트는 Forwarded에만 있을 수 있음)
+type hop struct {
+	ip   netip.Addr
+	port string
+}
+
+// Resolve: 요청의 실제 클라이언트 주소("IP:포트")를 반환합니다.
+// 연결 상대가 신뢰하는 프록시가 아니면 헤더를 무시하고 r.RemoteAddr을 그대로 반환합니다(위조 방지).
+// 전달 경로는 오른쪽(가장 가까운 프록시)부터 거슬러 올라가며, 신뢰하지 않는 첫 주소를 클라이언트로 봅니다.
+func (res *Resolver) Resolve(r *http.Request) string {
+	peer, ok := res.trustedPeer(r.RemoteAddr)
+	if !ok {
+		return r.RemoteAddr
+	}
+
+	hops, ok := forwarded(r.Header)
+	if !ok {
+		hops, ok = xForwardedFor(r.Header)
+	}
+	if !ok {
+		if ip, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+			hops, ok = []hop{{ip: ip.Unmap()}}, true
+		}
+	}
+	if !ok {
+		return r.RemoteAddr
+	}
+
+	client := hop{ip: peer}
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !hops[i].ip.IsValid() {
+			break // "unknown" 또는 숨긴 식별자: 그 앞은 확인할 수 없으므로 마지막으로 확인한 주소 사용
+		}
+		client = hops[i]
+		if !res.Trusted(client.ip) {
+			break
+		}
+	}
+	if !client.ip.IsValid() {
+		return r.RemoteAddr // Unix 소켓 프록시가 확인할 수 없는 주소만 전달함
+	}
+	port := client.port
+	if port == "" {
+		port = "0" // 헤더에 포트가 없으면 알 수 없음 (프록시의 포트는 클라이언트와 무관)
+	}
+	return net.JoinHostPort(client.ip.String(), port)
+}
+
+// addrIP: "IP:포트" 또는 IP 문자열에서 IP를 꺼냅니다.
+func addrIP(addr string) (netip.Addr, bool) {
+	if ap, err := netip.ParseAddrPort(addr); err == nil {
+		return ap.Addr().Unmap(), true
+	}
+	ip, err := netip.ParseAddr(addr)
+	return ip.Unmap(), err == nil
+}
+
+// forwarded: RFC 7239 Forwarded 헤더의 for= 값을 순서대로 꺼냅니다. 헤더가 없으면 ok가 false입니다.
+// 예: Forwarded: for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8:cafe::17]:4711"
+func forwarded(h http.Header) (hops []hop, ok bool) {
+	values := h.Values("Forwarded")
+	if len(values) == 0 {
+		return nil, false
+	}
+	for _, v := range values {
+		for _, elem := range strings.Split(v, ",") {
+			found := false
+			for _, pair := range strings.Split(elem, ";") {
+				k, val, _ := strings.Cut(strings.TrimSpace(pair), "=")
+				if !strings.EqualFold(k, "for") {
+					continue
+				}
+				found = true
+				hops = append(hops, parseNode(strings.Trim(val, `"`)))
+			}
+			if !found {
+				hops = append(hops, hop{}) // for=가 없는 항목은 확인할 수 없는 홉
+			}
+		}
+	}
+	return hops, true
+}
+
+// parseNode: Forwarded의 node 값("192.0.2.60", "192.0.2.60:47011", "[2001:db8::1]:4711", "unknown", "_hidden")
+func parseNode(node string) hop {
+	if ap, err := netip.ParseAddrPort(node); err == nil {
+		return hop{ip: ap.Addr().Unmap(), port: fmt.Sprint(ap.Port())}
+	}
+	if ip, err := netip.ParseAddr(strings.Trim(node, "[]")); err == nil {
+		return hop{ip: ip.Unmap()}
+	}
+	return hop{} // 잘못된 값 또는 숨긴 식별자
+}
+
+// xForwardedFor: X-Forwarded-For 목록("client, proxy1, proxy2")을 꺼냅니다. 헤더가 없으면 ok가 false입니다.
+func xForwardedFor(h http.Header) (hops []hop, ok bool) {
+	values := h.Values("X-Forwarded-For")
+	if len(values) == 0 {
+		return nil, false
+	}
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			ip, err := netip.ParseAddr(strings.TrimSpace(s))
+			if err != nil {
+				hops = append(hops, hop{})
+				continue
+			}
+			hops = append(hops, hop{ip: ip.Unmap()})
+		}
+	}
+	return hops, true
+}
+
+type peerKey struct{}
+
+// Peer: 요청을 보낸 연결 상대(프록시)의 주소. Middleware를 거치지 않았으면 r.RemoteAddr입니다.
+func Peer(r *http.Request) string {
+	if peer, ok := r.Context().Value(peerKey{}).(string); ok {
+		return peer
+	}
+	return r.RemoteAddr
+}
+
+// Middleware: r.RemoteAddr을 실제 클라이언트 주소로 바꾼 뒤 다음 핸들러를 호출합니다.
+func (res *Resolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := res.Resolve(r)
+		if client != r.RemoteAddr {
+			ctx := context.WithValue(r.Context(), peerKey{}, r.RemoteAddr)
+			r = r.WithContext(ctx)
+			r.RemoteAddr = client
+		}
+		next.ServeHTTP(w, r)
+	})
+}
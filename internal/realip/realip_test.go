@@ -0,0 +1,367 @@
+package realip
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTrusted(t *testing.T) {
+	tests := []struct {
+		list    string
+		wantErr bool
+	}{
+		{"10.0.0.0/8", false},
+		{"10.0.0.0/8, 192.168.1.10, ::1", false},
+		{"unix", false},
+		{"10.0.0.0/33", true},
+		{"10.0.0.x", true},
+		{"", true},
+		{" , ", true},
+	}
+	for _, tt := range tests {
+		_, err := ParseTrusted(tt.list)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTrusted(%q) error = %v, wantErr %v", tt.list, err, tt.wantErr)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted string
+		remote  string
+		headers map[string][]string
+		want    string
+	}{
+		{
+			name:    "untrusted peer cannot spoof X-Forwarded-For",
+			trusted: "10.0.0.0/8",
+			remote:  "203.0.113.9:1234",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.7"}},
+			want:    "203.0.113.9:1234",
+		},
+		{
+			name:    "untrusted peer cannot spoof Forwarded or X-Real-IP",
+			trusted: "10.0.0.0/8",
+			remote:  "203.0.113.9:1234",
+			headers: map[string][]string{"Forwarded": {"for=198.51.100.7"}, "X-Real-Ip": {"198.51.100.8"}},
+			want:    "203.0.113.9:1234",
+		},
+		{
+			name:    "trusted peer without headers",
+			trusted: "10.0.0.0/8",
+			remote:  "10.0.0.1:5000",
+			want:    "10.0.0.1:5000",
+		},
+		{
+			name:    "single hop",
+			trusted: "10.0.0.0/8",
+			remote:  "10.0.0.1:5000",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.7"}},
+			want:    "198.51.100.7:0",
+		},
+		{
+			name:    "walks trusted hops and stops at the first untrusted one",
+			trusted: "10.0.0.0/8",
+			remote:  "10.0.0.1:5000",
+			headers: map[string][]string{"X-Forwarded-For": {"6.6.6.6, 198.51.100.7, 10.0.0.2"}},
+			want:    "198.51.100.7:0",
+		},
+		{
+			name:    "repeated headers are one list",
+			trusted: "10.0.0.0/8",
+			remote:  "10.0.0.1:5000",
+			headers: map[string][]string{"X-Forwarded-For": {"6.6.6.6", "198.51.100.7", "10.0.0.2"}},
+			want:    "198.51.100.7:0",
+		},
+		{
+			name:    "all hops trusted uses the leftmost",
+			trusted: "10.0.0.0/8",
+			remote:  "10.0.0.1:5000",
+			headers: map[string][]string{"X-Forwarded-For": {"10.0.0.5, 10.0.0.6"}},
+			want:    "10.0.0.5:0",
+		},
+		{
+			name:    "garbage hop stops the walk at the last verified address",
+			trusted: "10.0.0.0/8",
+			remote:  "10.0.0.1:5000",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.7, not-an-ip"}},
+			want:    "10.0.0.1:0",
+		},
+		{
+			name:    "Forwarded wins over X-Forwarded-For",
+			trusted: "10.0.0.0/8",
+			remote:  "10.0.0.1:5000",
+			headers: map[string][]string{
+				"Forwarded":       {`for="[2001:db8::1]:4711";proto=https`},
+				"X-Forwarded-For": {"198.51.100.7"},
+			},
+			want: "[2001:db8::1]:4711",
+		},
+		{
+			name:    "Forwarded walks trusted hops",
+			trusted: "10.0.0.0/8",
+			remote:  "10.0.0.1:5000",
+			headers: map[string][]string{"Forwarded": {"for=6.6.6.6, for=192.0.2.60:47011, for=10.0.0.2;by=10.0.0.1"}},
+			want:    "192.0.2.60:47011",
+		},
+		{
+			name:    "Forwarded unknown node stops the walk",
+			trusted: "10.0.0.0/8",
+			remote:  "10.0.0.1:5000",
+			headers: map[string][]string{"Forwarded": {"for=198.51.100.1, for=unknown"}},
+			want:    "10.0.0.1:0",
+		},
+		{
+			name:    "Forwarded element without for= is unverifiable",
+			trusted: "10.0.0.0/8",
+			remote:  "10.0.0.1:5000",
+			headers: map[string][]string{"Forwarded": {"for=198.51.100.1, proto=https"}},
+			want:    "10.0.0.1:0",
+		},
+		{
+			name:    "X-Real-IP as last resort",
+			trusted: "10.0.0.0/8",
+			remote:  "10.0.0.1:5000",
+			headers: map[string][]string{"X-Real-Ip": {" 198.51.100.9 "}},
+			want:    "198.51.100.9:0",
+		},
+		{
+			name:    "invalid X-Real-IP is ignored",
+			trusted: "10.0.0.0/8",
+			remote:  "10.0.0.1:5000",
+			headers: map[string][]string{"X-Real-Ip": {"nope"}},
+			want:    "10.0.0.1:5000",
+		},
+		{
+			name:    "IPv4-mapped peer matches an IPv4 CIDR",
+			trusted: "10.0.0.0/8",
+			remote:  "[::ffff:10.0.0.1]:5000",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.7"}},
+			want:    "198.51.100.7:0",
+		},
+		{
+			name:    "single trusted IP",
+			trusted: "192.168.1.10",
+			remote:  "192.168.1.11:5000",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.7"}},
+			want:    "192.168.1.11:5000",
+		},
+		{
+			name:    "trusted unix socket peer",
+			trusted: "unix",
+			remote:  "@",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.7"}},
+			want:    "198.51.100.7:0",
+		},
+		{
+			name:    "unix socket peer is untrusted unless listed",
+			trusted: "10.0.0.0/8",
+			remote:  "@",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.7"}},
+			want:    "@",
+		},
+		{
+			name:    "unix socket peer with only unverifiable hops",
+			trusted: "unix",
+			remote:  "@",
+			headers: map[string][]string{"X-Forwarded-For": {"unknown"}},
+			want:    "@",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ParseTrusted(tt.trusted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			for k, vs := range tt.headers {
+				for _, v := range vs {
+					r.Header.Add(k, v)
+				}
+			}
+			if got := res.Resolve(r); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddlewarePeer(t *testing.T) {
+	res, err := ParseTrusted("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var remote, peer string
+	h := res.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remote, peer = r.RemoteAddr, Peer(r)
+	}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:5000"
+	r.Header.Set("X-Forwarded-For", "198.51.100.7")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if remote != "198.51.100.7:0" || peer != "10.0.0.1:5000" {
+		t.Errorf("proxied: RemoteAddr = %q, Peer = %q", remote, peer)
+	}
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "203.0.113.9:1234"
+	r.Header.Set("X-Forwarded-For", "198.51.100.7")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if remote != "203.0.113.9:1234" || peer != "203.0.113.9:1234" {
+		t.Errorf("direct: RemoteAddr = %q, Peer = %q", remote, peer)
+	}
+}
+
+// proxyV2Header: 테스트용 PROXY v2 헤더 (verCmd, famProto, 본문)
+func proxyV2Header(verCmd, famProto byte, body []byte) []byte {
+	h := append([]byte{}, proxyV2Sig...)
+	h = append(h, verCmd, famProto)
+	h = binary.BigEndian.AppendUint16(h, uint16(len(body)))
+	return append(h, body...)
+}
+
+func TestReadProxyV1(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		src, dst string // 비어 있으면 주소 없음 (연결 상대 주소 사용)
+		wantErr  bool
+	}{
+		{"tcp4", "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n", "192.0.2.1:56324", "198.51.100.1:443", false},
+		{"tcp6", "PROXY TCP6 2001:db8::1 2001:db8::2 4711 443\r\n", "[2001:db8::1]:4711", "[2001:db8::2]:443", false},
+		{"unknown", "PROXY UNKNOWN\r\n", "", "", false},
+		{"unknown with addresses", "PROXY UNKNOWN ffff:f...f ffff:f...f 65535 65535\r\n", "", "", false},
+		{"missing field", "PROXY TCP4 192.0.2.1 198.51.100.1 56324\r\n", "", "", true},
+		{"unsupported protocol", "PROXY UDP4 192.0.2.1 198.51.100.1 1 2\r\n", "", "", true},
+		{"bad address", "PROXY TCP4 192.0.2.x 198.51.100.1 56324 443\r\n", "", "", true},
+		{"bad port", "PROXY TCP4 192.0.2.1 198.51.100.1 70000 443\r\n", "", "", true},
+		{"family mismatch", "PROXY TCP4 2001:db8::1 198.51.100.1 1 2\r\n", "", "", true},
+		{"LF only", "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\n", "", "", true},
+		{"no terminator", "PROXY TCP4 " + strings.Repeat("1", 200), "", "", true},
+		{"truncated", "PROXY TCP4 192.0.2.1", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, dst, err := readProxyV1(bufio.NewReader(strings.NewReader(tt.header)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if addrString(src) != tt.src || addrString(dst) != tt.dst {
+				t.Errorf("got %s -> %s, want %s -> %s", addrString(src), addrString(dst), tt.src, tt.dst)
+			}
+		})
+	}
+}
+
+func TestReadProxyV2(t *testing.T) {
+	ipv4 := []byte{192, 0, 2, 1, 198, 51, 100, 1, 0xdc, 0x04, 0x01, 0xbb}
+	ipv6 := append(append(net.ParseIP("2001:db8::1").To16(), net.ParseIP("2001:db8::2").To16()...), 0x12, 0x67, 0x01, 0xbb)
+	tests := []struct {
+		name     string
+		header   []byte
+		src, dst string
+		wantErr  bool
+	}{
+		{"tcp4", proxyV2Header(0x21, 0x11, ipv4), "192.0.2.1:56324", "198.51.100.1:443", false},
+		{"tcp6", proxyV2Header(0x21, 0x21, ipv6), "[2001:db8::1]:4711", "[2001:db8::2]:443", false},
+		{"tcp4 with TLVs", proxyV2Header(0x21, 0x11, append(append([]byte{}, ipv4...), 0x01, 0x00, 0x02, 'h', '2')), "192.0.2.1:56324", "198.51.100.1:443", false},
+		{"local command", proxyV2Header(0x20, 0x00, nil), "", "", false},
+		{"unspec family", proxyV2Header(0x21, 0x00, nil), "", "", false},
+		{"udp is ignored", proxyV2Header(0x21, 0x12, ipv4), "", "", false},
+		{"bad version", proxyV2Header(0x11, 0x11, ipv4), "", "", true},
+		{"bad command", proxyV2Header(0x22, 0x11, ipv4), "", "", true},
+		{"short address block", proxyV2Header(0x21, 0x11, ipv4[:8]), "", "", true},
+		{"short ipv6 block", proxyV2Header(0x21, 0x21, ipv4), "", "", true},
+		{"truncated body", proxyV2Header(0x21, 0x11, ipv4)[:20], "", "", true},
+		{"truncated header", proxyV2Sig, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, dst, err := readProxyV2(bufio.NewReader(strings.NewReader(string(tt.header))))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if addrString(src) != tt.src || addrString(dst) != tt.dst {
+				t.Errorf("got %s -> %s, want %s -> %s", addrString(src), addrString(dst), tt.src, tt.dst)
+			}
+		})
+	}
+}
+
+func addrString(a net.Addr) string {
+	if a == nil {
+		return ""
+	}
+	return a.String()
+}
+
+func TestProxyListener(t *testing.T) {
+	tests := []struct {
+		name       string
+		trusted    string
+		send       string
+		wantRemote string // 비어 있으면 실제 연결 상대 주소
+		wantData   string
+		wantErr    bool
+	}{
+		{"trusted v1", "127.0.0.1", "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nhello", "192.0.2.1:56324", "hello", false},
+		{"trusted v2", "127.0.0.1", string(proxyV2Header(0x21, 0x11, []byte{192, 0, 2, 1, 198, 51, 100, 1, 0xdc, 0x04, 0x01, 0xbb})) + "hello", "192.0.2.1:56324", "hello", false},
+		{"trusted without header", "127.0.0.1", "hello", "", "hello", false},
+		{"trusted malformed header", "127.0.0.1", "PROXY TCP4 nonsense\r\nhello", "", "", true},
+		{"untrusted header is passed through", "10.0.0.0/8", "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nhello", "", "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nhello", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ParseTrusted(tt.trusted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			inner, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			l := &ProxyListener{Listener: inner, Resolver: res, HeaderTimeout: time.Second}
+			defer l.Close()
+
+			client, err := net.Dial("tcp", inner.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.WriteString(client, tt.send)
+			client.(*net.TCPConn).CloseWrite()
+			defer client.Close()
+
+			conn, err := l.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			data, err := io.ReadAll(conn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("read error = %v, wantErr %v", err, tt.wantErr)
+			}
+			want := tt.wantRemote
+			if want == "" {
+				want = client.LocalAddr().String()
+			}
+			if got := conn.RemoteAddr().String(); got != want {
+				t.Errorf("RemoteAddr() = %q, want %q", got, want)
+			}
+			if string(data) != tt.wantData {
+				t.Errorf("data = %q, want %q", data, tt.wantData)
+			}
+		})
+	}
+}
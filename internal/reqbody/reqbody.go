@@ -0,0 +1,165 @@
+// Package reqbody는 요청 본문을 Content-Type에 따라 해석하여 url.Values로 변환합니다.
+// application/x-www-form-urlencoded, application/json, multipart/form-data를 지원합니다.
+package reqbody
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Limits: 형식별 본문 크기 제한 (바이트)
+type Limits struct {
+	Form      int64
+	JSON      int64
+	Multipart int64
+}
+
+// DefaultLimits: 기본 크기 제한
+var DefaultLimits = Limits{
+	Form:      64 << 10,
+	JSON:      64 << 10,
+	Multipart: 1 << 20,
+}
+
+// SupportedTypes: 지원하는 Content-Type 목록 (415 응답의 Accept-Post 헤더에 사용)
+var SupportedTypes = []string{"application/x-www-form-urlencoded", "application/json", "multipart/form-data"}
+
+// Error: HTTP 상태 코드를 포함한 본문 해석 오류
+type Error struct {
+	Status int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+func errorf(status int, format string, args ...any) *Error {
+	return &Error{Status: status, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Values: 요청 본문을 Content-Type에 맞게 해석합니다.
+// Content-Type이 없으면 기존 클라이언트와의 호환을 위해 URL 인코딩 폼으로 처리합니다.
+// 오류는 항상 *Error 입니다. (413, 415, 400)
+func Values(w http.ResponseWriter, r *http.Request, limits Limits) (url.Values, error) {
+	contentType := r.Header.Get("Content-Type")
+	mediaType := "application/x-www-form-urlencoded"
+	var params map[string]string
+	if contentType != "" {
+		var err error
+		mediaType, params, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, errorf(http.StatusBadRequest, "Malformed Content-Type header: %v", err)
+		}
+	}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		body, err := readLimited(w, r, limits.Form)
+		if err != nil {
+			return nil, err
+		}
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, errorf(http.StatusBadRequest, "Error parsing form data: %v", err)
+		}
+		return values, nil
+
+	case "application/json":
+		body, err := readLimited(w, r, limits.JSON)
+		if err != nil {
+			return nil, err
+		}
+		return JSONValues(body)
+
+	case "multipart/form-data":
+		if params["boundary"] == "" {
+			return nil, errorf(http.StatusBadRequest, "multipart/form-data requires a boundary parameter")
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limits.Multipart)
+		// 제한 크기 안에서는 파일 파트도 메모리에 보관 (디스크 임시 파일을 만들지 않음)
+		if err := r.ParseMultipartForm(limits.Multipart); err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				return nil, errorf(http.StatusRequestEntityTooLarge, "Request body exceeds %d bytes", limits.Multipart)
+			}
+			return nil, errorf(http.StatusBadRequest, "Error parsing multipart form: %v", err)
+		}
+		defer r.MultipartForm.RemoveAll()
+		return url.Values(r.MultipartForm.Value), nil
+
+	default:
+		return nil, errorf(http.StatusUnsupportedMediaType, "Unsupported Content-Type %q. Use %s.", mediaType, strings.Join(SupportedTypes, ", "))
+	}
+}
+
+// readLimited: 최대 limit 바이트까지 본문을 읽습니다.
+func readLimited(w http.ResponseWriter, r *http.Request, limit int64) ([]byte, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, errorf(http.StatusRequestEntityTooLarge, "Request body exceeds %d bytes", limit)
+		}
+		return nil, errorf(http.StatusBadRequest, "Error reading request body: %v", err)
+	}
+	return body, nil
+}
+
+// JSONValues: JSON 객체의 필드를 url.Values로 변환합니다. ({"var1":9,"var2":9} -> var1=9&var2=9)
+// 숫자는 원문 그대로 보존하고, 배열은 같은 키의 여러 값이 됩니다.
+func JSONValues(body []byte) (url.Values, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return nil, errorf(http.StatusBadRequest, "Invalid JSON body: %v", err)
+	}
+	if dec.More() {
+		return nil, errorf(http.StatusBadRequest, "Invalid JSON body: unexpected data after the object")
+	}
+
+	values := url.Values{}
+	for key, v := range obj {
+		items := []any{v}
+		if arr, ok := v.([]any); ok {
+			items = arr
+		}
+		for _, item := range items {
+			s, err := scalarString(item)
+			if err != nil {
+				return nil, errorf(http.StatusBadRequest, "Invalid JSON field %q: %v", key, err)
+			}
+			if item != nil {
+				values.Add(key, s)
+			}
+		}
+	}
+	return values, nil
+}
+
+func scalarString(v any) (string, error) {
+	switch x := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return x, nil
+	case json.Number:
+		return x.String(), nil
+	case bool:
+		if x {
+			return "true", nil
+		}
+		return "false", nil
+	default:
+		return "", errors.New("must be a string, number, boolean or an array of them")
+	}
+}
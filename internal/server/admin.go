@@ -0,0 +1,141 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"expvar"
+	"flag"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"strings"
+	"time"
+
+	"full_stack_service_networking_project/internal/accesslog"
+	"full_stack_service_networking_project/internal/metrics"
+)
+
+// expvar와 net/http/pprof는 import만 해도 http.DefaultServeMux에 /debug/vars, /debug/pprof/를 등록합니다.
+// 서버 프로그램은 각자의 ServeMux를 사용하므로 이 경로들은 관리 포트(AdminConfig.Addr)에서만 노출됩니다.
+
+// requestVars: /debug/vars의 "http_requests" 항목 (total, in_flight, 상태 코드 계열별 응답 수)
+var requestVars = expvar.NewMap("http_requests")
+
+// AdminConfig: 관리용 HTTP 리스너 설정 (상태 확인, 런타임 진단)
+type AdminConfig struct {
+	Addr  string // 관리 포트 주소 (비어 있으면 관리 리스너를 열지 않음). 공개 포트와 다른 내부 주소 권장
+	Token string // /debug/ 경로에 요구하는 Bearer 토큰 (pprof는 필수)
+	Pprof bool   // 관리 포트에 /debug/pprof/를 노출
+}
+
+func (c *AdminConfig) registerFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Addr, "admin-addr", c.Addr, "internal address for /healthz, /readyz, /metrics, /debug/vars and optional pprof, e.g. 127.0.0.1:6060 (empty: disabled)")
+	fs.StringVar(&c.Token, "admin-token", c.Token, "bearer token required for /debug/ endpoints on the admin address (required with -pprof)")
+	fs.BoolVar(&c.Pprof, "pprof", c.Pprof, "serve net/http/pprof under /debug/pprof/ on -admin-addr")
+}
+
+// validate: pprof는 관리 포트에서만, 토큰 인증과 함께 사용할 수 있습니다.
+func (c *AdminConfig) validate() error {
+	if c.Pprof && c.Addr == "" {
+		return errors.New("-pprof requires -admin-addr (pprof is never served on the public port)")
+	}
+	if c.Pprof && c.Token == "" {
+		return errors.New("-pprof requires -admin-token")
+	}
+	return nil
+}
+
+// httpMetrics: 라우트·메서드·상태 코드별 HTTP 메트릭 (/metrics)
+type httpMetrics struct {
+	requests *metrics.CounterVec   // http_requests_total{route,method,status}
+	duration *metrics.HistogramVec // http_request_duration_seconds{route,method}
+	inFlight *metrics.Gauge        // http_requests_in_flight
+}
+
+func newHTTPMetrics(reg *metrics.Registry) *httpMetrics {
+	return &httpMetrics{
+		requests: reg.NewCounterVec("http_requests_total", "HTTP requests by route pattern, method and status code.", "route", "method", "status"),
+		duration: reg.NewHistogramVec("http_request_duration_seconds", "Time to serve HTTP requests, including streaming responses.", nil, "route", "method"),
+		inFlight: reg.NewGauge("http_requests_in_flight", "HTTP requests currently being served."),
+	}
+}
+
+// metricMethods: 메트릭 레이블로 그대로 쓰는 메서드 (나머지는 "OTHER"로 묶어 시계열 수를 제한)
+var metricMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+// route: 요청을 처리할 ServeMux 패턴 (요청 경로 대신 사용해 시계열 수를 제한, 일치하는 라우트가 없으면 "none")
+func (s *Server) route(r *http.Request) string {
+	if mux, ok := s.handler.(*http.ServeMux); ok {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			return pattern
+		}
+		return "none"
+	}
+	return "all"
+}
+
+// instrument: 요청 수, 처리 중인 요청 수, 응답 상태, 처리 시간을 expvar(requestVars)와 Metrics에 기록하는 미들웨어
+func (s *Server) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		route, method := s.route(r), r.Method
+		if !metricMethods[method] {
+			method = "OTHER"
+		}
+		requestVars.Add("total", 1)
+		requestVars.Add("in_flight", 1)
+		s.httpMetrics.inFlight.Inc()
+		defer func() {
+			requestVars.Add("in_flight", -1)
+			s.httpMetrics.inFlight.Dec()
+		}()
+
+		rw := accesslog.NewResponseWriter(w)
+		next.ServeHTTP(rw, r)
+		status := rw.Status()
+		requestVars.Add(strconv.Itoa(status/100)+"xx", 1)
+		s.httpMetrics.requests.With(route, method, strconv.Itoa(status)).Inc()
+		s.httpMetrics.duration.With(route, method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// requireToken: "Authorization: Bearer <token>"이 일치해야 next를 호출합니다.
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, got, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimSpace(got)), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// adminHandler: 관리 포트의 라우트
+func (s *Server) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", s.Health.LivenessHandler())
+	mux.Handle("/readyz", s.Health.ReadinessHandler())
+	mux.Handle("/metrics", s.Metrics.Handler())
+
+	debug := http.NewServeMux()
+	debug.Handle("/debug/vars", expvar.Handler())
+	if s.Config.Admin.Pprof {
+		debug.HandleFunc("/debug/pprof/", pprof.Index)
+		debug.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		debug.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		debug.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		debug.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+	if s.Config.Admin.Token != "" {
+		mux.Handle("/debug/", requireToken(s.Config.Admin.Token, debug))
+	} else {
+		mux.Handle("/debug/", debug)
+	}
+	return mux
+}
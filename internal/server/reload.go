@@ -0,0 +1,81 @@
+package server
+
+import (
+	"net/http"
+
+	"full_stack_service_networking_project/internal/compress"
+	"full_stack_service_networking_project/internal/cors"
+	"full_stack_service_networking_project/internal/ratelimit"
+)
+
+// buildChain: cfg에 따라 CORS → 속도 제한 → 동시 요청 제한 → 압축 미들웨어를 s.base에 씌웁니다. (s.mu를 쥔 상태에서 호출)
+func (s *Server) buildChain(cfg Config) (http.Handler, error) {
+	h := s.base
+	if cfg.Compress {
+		c, err := compress.New(compress.Config{MinSize: cfg.CompressMinSize, Level: cfg.CompressLevel})
+		if err != nil {
+			return nil, err
+		}
+		h = c.Middleware(h)
+	}
+	if cfg.MaxInFlight > 0 {
+		f := ratelimit.NewInFlight(cfg.MaxInFlight)
+		f.RenderError = s.ErrorHandler
+		h = f.Middleware(h)
+	}
+	if cfg.RateLimit > 0 {
+		mux, _ := s.handler.(*http.ServeMux)
+		l, err := ratelimit.New(ratelimit.Config{
+			Rate:         cfg.RateLimit,
+			Burst:        cfg.RateBurst,
+			Key:          cfg.RateKey,
+			APIKeyHeader: cfg.RateAPIKeyHeader,
+			APIKeys:      splitList(cfg.RateAPIKeys),
+		}, mux)
+		if err != nil {
+			return nil, err
+		}
+		l.RenderError = s.ErrorHandler
+		h = l.Middleware(h)
+	}
+	if cfg.CORSPolicy != "" {
+		// 사전 요청(OPTIONS)은 라우트별 핸들러에 닿기 전에 응답하도록 교체 가능한 미들웨어 중 가장 바깥에 둠
+		c, err := cors.Load(cfg.CORSPolicy)
+		if err != nil {
+			return nil, err
+		}
+		h = c.Middleware(h)
+	}
+	return h, nil
+}
+
+// Reload: 재시작 없이 바꿀 수 있는 설정(ReloadableFlags)을 cfg의 값으로 교체합니다. 나머지 필드는 무시합니다.
+// CORS 정책 파일은 경로가 같아도 다시 읽습니다. 새 설정이 잘못되었으면 기존 설정을 유지하고 오류를 반환합니다.
+// 속도 제한 버킷과 동시 요청 수는 새로 시작합니다. (교체 전에 시작된 요청은 기존 제한으로 끝까지 처리)
+func (s *Server) Reload(cfg Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := s.Config
+	next.CORSPolicy = cfg.CORSPolicy
+	next.Compress = cfg.Compress
+	next.CompressMinSize = cfg.CompressMinSize
+	next.CompressLevel = cfg.CompressLevel
+	next.RateLimit = cfg.RateLimit
+	next.RateBurst = cfg.RateBurst
+	next.RateKey = cfg.RateKey
+	next.RateAPIKeyHeader = cfg.RateAPIKeyHeader
+	next.RateAPIKeys = cfg.RateAPIKeys
+	next.MaxInFlight = cfg.MaxInFlight
+	if err := next.Validate(); err != nil {
+		return err
+	}
+	if s.base != nil { // 시작 전이면 RunContext가 새 설정으로 만듦
+		h, err := s.buildChain(next)
+		if err != nil {
+			return err
+		}
+		s.live.Store(&h)
+	}
+	s.Config = next
+	return nil
+}
@@ -0,0 +1,520 @@
+// Package server는 계산기 서버와 멤버십 REST 서버가 공통으로 사용하는 http.Server 구성과
+// SIGINT/SIGTERM 수신 시의 우아한 종료(graceful shutdown) 절차를 제공합니다.
+package server
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"full_stack_service_networking_project/internal/compress"
+	"full_stack_service_networking_project/internal/health"
+	"full_stack_service_networking_project/internal/identity"
+	"full_stack_service_networking_project/internal/listener"
+	"full_stack_service_networking_project/internal/metrics"
+	"full_stack_service_networking_project/internal/ratelimit"
+	"full_stack_service_networking_project/internal/rawhttp"
+	"full_stack_service_networking_project/internal/realip"
+)
+
+// 서버 엔진: 표준 net/http 또는 internal/rawhttp의 직접 구현한 HTTP/1.1
+const (
+	EngineNetHTTP = "net/http"
+	EngineRaw     = "raw"
+)
+
+// Config: http.Server 타임아웃과 종료 대기 시간 설정
+type Config struct {
+	Addr              string        // 쉼표로 구분한 리스너 주소: host:port, unix:<경로>, systemd[:<이름>] (internal/listener)
+	Engine            string        // EngineNetHTTP 또는 EngineRaw
+	ReadTimeout       time.Duration // 요청 전체(헤더+본문)를 읽는 최대 시간
+	ReadHeaderTimeout time.Duration // 요청 헤더를 읽는 최대 시간 (Slowloris 방지)
+	WriteTimeout      time.Duration // 응답을 쓰는 최대 시간
+	IdleTimeout       time.Duration // keep-alive 연결의 최대 유휴 시간
+	MaxHeaderBytes    int           // 요청 헤더의 최대 크기
+	ShutdownTimeout   time.Duration // 종료 신호 후 처리 중인 요청을 기다리는 최대 시간
+	CORSPolicy        string        // CORS 정책 JSON 파일 (비어 있으면 CORS 헤더를 보내지 않음)
+	Compress          bool          // Accept-Encoding에 따라 응답을 gzip/deflate로 압축
+	CompressMinSize   int           // 이보다 작은 응답은 압축하지 않음 (바이트)
+	CompressLevel     int           // 압축 수준 1~9 (0: 기본값)
+	RateLimit         float64       // 클라이언트별 초당 요청 수 제한 (0: 제한 없음)
+	RateBurst         int           // 한꺼번에 허용하는 요청 수 (0: RateLimit을 올림한 값)
+	RateKey           string        // 속도 제한 버킷 기준: ip, apikey, route 또는 조합 (예: ip,route)
+	RateAPIKeyHeader  string        // apikey 기준에서 API 키를 읽는 헤더
+	RateAPIKeys       string        // apikey 기준에서 인정하는 API 키 목록 (쉼표 구분, 목록에 없는 키는 IP로 구분)
+	MaxInFlight       int           // 동시에 처리하는 요청 수 제한, 넘치면 503 (0: 제한 없음)
+	SocketMode        string        // 서버가 만드는 Unix 소켓 파일의 권한 (8진수, 예: 0660)
+	SocketOwner       string        // 서버가 만드는 Unix 소켓 파일의 소유자 user[:group] (비어 있으면 바꾸지 않음)
+	TrustedProxies    string        // 전달 헤더와 PROXY 프로토콜을 믿을 프록시 CIDR/IP 목록 (쉼표 구분, "unix"는 Unix 소켓 연결, 비어 있으면 믿지 않음)
+	ProxyProtocol     bool          // 신뢰하는 프록시의 연결에서 HAProxy PROXY 프로토콜 v1/v2 헤더를 받음
+	TLS               TLSConfig
+	Admin             AdminConfig
+}
+
+// DefaultConfig: 기본 설정
+func DefaultConfig(addr string) Config {
+	return Config{
+		Addr:              addr,
+		Engine:            EngineNetHTTP,
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		MaxHeaderBytes:    64 << 10,
+		ShutdownTimeout:   20 * time.Second,
+		Compress:          true,
+		CompressMinSize:   compress.DefaultMinSize,
+		RateKey:           ratelimit.KeyIP,
+		RateAPIKeyHeader:  ratelimit.DefaultAPIKeyHeader,
+		SocketMode:        "0660",
+		TLS: TLSConfig{
+			DevDir:     "certs",
+			DevHosts:   "localhost,127.0.0.1,::1",
+			MinVersion: "1.2",
+		},
+	}
+}
+
+// RegisterFlags: 설정 항목을 명령행 플래그로 등록합니다. (현재 값이 기본값이 됩니다)
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Addr, "addr", c.Addr, "comma-separated listen addresses: host:port, unix:/path/to.sock, or systemd[:name] for sockets passed by systemd (LISTEN_FDS)")
+	fs.StringVar(&c.SocketMode, "socket-mode", c.SocketMode, "file mode (octal) of Unix sockets created for -addr unix:<path>")
+	fs.StringVar(&c.SocketOwner, "socket-owner", c.SocketOwner, "owner of Unix sockets created for -addr unix:<path>, as user, user:group or :group")
+	fs.StringVar(&c.Engine, "engine", c.Engine, "HTTP server engine: net/http or raw (from-scratch HTTP/1.1)")
+	fs.DurationVar(&c.ReadTimeout, "read-timeout", c.ReadTimeout, "maximum duration for reading an entire request")
+	fs.DurationVar(&c.ReadHeaderTimeout, "read-header-timeout", c.ReadHeaderTimeout, "maximum duration for reading request headers")
+	fs.DurationVar(&c.WriteTimeout, "write-timeout", c.WriteTimeout, "maximum duration for writing a response")
+	fs.DurationVar(&c.IdleTimeout, "idle-timeout", c.IdleTimeout, "maximum keep-alive idle time")
+	fs.IntVar(&c.MaxHeaderBytes, "max-header-bytes", c.MaxHeaderBytes, "maximum size of request headers")
+	fs.DurationVar(&c.ShutdownTimeout, "shutdown-timeout", c.ShutdownTimeout, "how long to drain in-flight requests on SIGINT/SIGTERM")
+	fs.BoolVar(&c.Compress, "compress", c.Compress, "compress responses with gzip or deflate when the client accepts it")
+	fs.IntVar(&c.CompressMinSize, "compress-min-size", c.CompressMinSize, "do not compress responses smaller than this many bytes")
+	fs.IntVar(&c.CompressLevel, "compress-level", c.CompressLevel, "compression level 1 (fastest) to 9 (smallest); 0 uses the default")
+	fs.Float64Var(&c.RateLimit, "rate-limit", c.RateLimit, "requests per second allowed per client (0: unlimited)")
+	fs.IntVar(&c.RateBurst, "rate-burst", c.RateBurst, "requests a client may make in a burst (0: rate-limit rounded up)")
+	fs.StringVar(&c.RateKey, "rate-key", c.RateKey, "what a rate limit bucket is keyed by: ip, apikey, route or a combination such as ip,route")
+	fs.StringVar(&c.RateAPIKeyHeader, "rate-api-key-header", c.RateAPIKeyHeader, "request header carrying the API key for -rate-key apikey")
+	fs.StringVar(&c.RateAPIKeys, "rate-api-keys", c.RateAPIKeys, "comma-separated API keys that get their own bucket with -rate-key apikey; other keys are limited by client IP (prefer the config file or env over the command line)")
+	fs.IntVar(&c.MaxInFlight, "max-in-flight", c.MaxInFlight, "maximum requests processed at once; extra requests get 503 (0: unlimited)")
+	fs.StringVar(&c.TrustedProxies, "trusted-proxies", c.TrustedProxies, "comma-separated CIDRs or IPs of proxies whose Forwarded, X-Forwarded-For and X-Real-IP headers are trusted (\"unix\": peers on Unix sockets)")
+	fs.BoolVar(&c.ProxyProtocol, "proxy-protocol", c.ProxyProtocol, "accept HAProxy PROXY protocol v1/v2 headers from -trusted-proxies")
+	fs.StringVar(&c.CORSPolicy, "cors-policy", c.CORSPolicy, "JSON file with the CORS policy for browser clients on other origins (empty: no CORS headers)")
+	c.TLS.registerFlags(fs)
+	c.Admin.registerFlags(fs)
+}
+
+// ReloadableFlags: 실행 중에 Reload로 바꿀 수 있는 설정의 플래그 이름
+// (주소, 엔진, 타임아웃, TLS, 신뢰하는 프록시처럼 리스너와 연결에 묶인 설정은 재시작해야 적용됩니다)
+var ReloadableFlags = []string{
+	"cors-policy", "compress", "compress-min-size", "compress-level",
+	"rate-limit", "rate-burst", "rate-key", "rate-api-key-header", "rate-api-keys", "max-in-flight",
+}
+
+// Validate: 설정 값의 범위와 서로 함께 써야 하는 설정을 확인합니다. (RunContext가 시작 전에 호출)
+func (c *Config) Validate() error {
+	switch c.Engine {
+	case "", EngineNetHTTP, EngineRaw:
+	default:
+		return fmt.Errorf("unknown -engine %q (want %s or %s)", c.Engine, EngineNetHTTP, EngineRaw)
+	}
+	if _, err := listener.Parse(c.Addr); err != nil {
+		return fmt.Errorf("-addr: %w", err)
+	}
+	if _, err := listener.ParseMode(c.SocketMode); err != nil {
+		return fmt.Errorf("-socket-mode: %w", err)
+	}
+	for name, d := range map[string]time.Duration{
+		"read-timeout": c.ReadTimeout, "read-header-timeout": c.ReadHeaderTimeout, "write-timeout": c.WriteTimeout,
+		"idle-timeout": c.IdleTimeout, "shutdown-timeout": c.ShutdownTimeout,
+	} {
+		if d < 0 {
+			return fmt.Errorf("-%s must not be negative, got %v", name, d)
+		}
+	}
+	for name, n := range map[string]int{
+		"max-header-bytes": c.MaxHeaderBytes, "compress-min-size": c.CompressMinSize,
+		"rate-burst": c.RateBurst, "max-in-flight": c.MaxInFlight,
+	} {
+		if n < 0 {
+			return fmt.Errorf("-%s must not be negative, got %d", name, n)
+		}
+	}
+	if c.RateLimit < 0 {
+		return fmt.Errorf("-rate-limit must not be negative, got %v", c.RateLimit)
+	}
+	if c.CompressLevel < 0 || c.CompressLevel > 9 {
+		return fmt.Errorf("-compress-level must be 0 (default) or 1-9, got %d", c.CompressLevel)
+	}
+	if err := c.Admin.validate(); err != nil {
+		return err
+	}
+	if err := c.TLS.validate(); err != nil {
+		return err
+	}
+	if !c.TLS.active() && c.TLS.ClientCAFile != "" {
+		return errors.New("-tls-client-ca (mTLS) requires -tls or -tls-dev")
+	}
+	if c.ProxyProtocol && c.TrustedProxies == "" {
+		return errors.New("-proxy-protocol requires -trusted-proxies")
+	}
+	return nil
+}
+
+// hookTimeout: 종료 시 정리 작업 전체에 허용하는 시간
+const hookTimeout = 10 * time.Second
+
+// Server: http.Server와 종료 시 실행할 정리 작업을 묶은 구조체
+type Server struct {
+	Config Config
+	HTTP   *http.Server
+
+	// Health: /healthz, /readyz 상태 확인. 종료가 시작되면 준비 상태가 내려갑니다.
+	Health *health.Checker
+
+	// Metrics: /metrics로 출력하는 메트릭 (HTTP 요청 메트릭은 자동으로 기록, 서버 프로그램이 도메인 메트릭을 추가)
+	Metrics *metrics.Registry
+
+	// ErrorHandler: 속도 제한(429)과 과부하(503) 거절 응답을 출력하는 함수 (nil이면 http.Error 사용)
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, status int, msg string)
+
+	handler http.Handler // New에 전달된 핸들러 (route 기준 속도 제한에서 ServeMux 패턴 조회)
+	base    http.Handler // 교체 가능한 미들웨어 안쪽의 핸들러 (RunContext 이후 설정)
+
+	live atomic.Pointer[http.Handler] // CORS → 속도 제한 → 동시 요청 제한 → 압축을 씌운 핸들러 (Reload로 교체)
+
+	raw      *rawhttp.Server  // Engine이 raw일 때 HTTP 대신 실행하는 서버
+	proxies  *realip.Resolver // 신뢰하는 프록시 목록 (TrustedProxies가 비어 있으면 nil)
+	redirect *http.Server     // HTTP→HTTPS 리다이렉트 서버 (선택)
+	admin    *http.Server     // 관리 포트 서버 (선택)
+
+	httpMetrics *httpMetrics
+
+	mu         sync.Mutex
+	hooks      []func(context.Context) error
+	startHooks []func()
+}
+
+// engine: net/http.Server와 rawhttp.Server에 공통인 종료 동작
+type engine interface {
+	Shutdown(ctx context.Context) error
+	Close() error
+	RegisterOnShutdown(f func())
+}
+
+// runner: 함께 실행/종료되는 서버 하나
+type runner struct {
+	engine
+	addr  string
+	serve func() error
+}
+
+// New: 설정을 적용한 Server를 생성합니다.
+func New(cfg Config, handler http.Handler) *Server {
+	checker := health.New()
+	reg := metrics.NewRegistry()
+	return &Server{
+		Config:      cfg,
+		Health:      checker,
+		Metrics:     reg,
+		httpMetrics: newHTTPMetrics(reg),
+		startHooks:  []func(){checker.SetDraining}, // 종료가 시작되면 /readyz가 503을 반환
+		handler:     handler,
+		HTTP: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           identity.Middleware(handler), // mTLS 클라이언트 신원을 컨텍스트로 전달
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		},
+	}
+}
+
+// Scheme: 서버가 사용하는 URL 스킴 ("http" 또는 "https")
+func (s *Server) Scheme() string {
+	if s.Config.TLS.active() {
+		return "https"
+	}
+	return "http"
+}
+
+// URL: 시작 메시지에 표시할 서버 주소 (호스트를 지정하지 않은 주소는 localhost로 표시, 예: http://localhost:8080)
+// 주소가 여러 개면 쉼표로 이어 붙이며, Unix 소켓은 클라이언트의 -server, -base-url 형식(unix://<경로>)으로 표시합니다.
+func (s *Server) URL() string {
+	addrs, err := listener.Parse(s.Config.Addr)
+	if err != nil {
+		return s.Scheme() + "://" + s.Config.Addr
+	}
+	urls := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		switch a.Network {
+		case listener.NetworkTCP:
+			host, port, _ := net.SplitHostPort(a.Address)
+			if host == "" || host == "0.0.0.0" || host == "::" {
+				host = "localhost"
+			}
+			urls = append(urls, s.Scheme()+"://"+net.JoinHostPort(host, port))
+		case listener.NetworkUnix:
+			u := "unix://" + a.Address
+			if s.Config.TLS.active() {
+				u += " (TLS)" // 클라이언트의 unix:// 주소는 평문 HTTP만 지원
+			}
+			urls = append(urls, u)
+		default:
+			urls = append(urls, s.Scheme()+" on "+a.String())
+		}
+	}
+	return strings.Join(urls, ", ")
+}
+
+// OnShutdown: 처리 중인 요청이 모두 끝난 뒤 실행할 정리 작업(상태 저장, 로그 flush 등)을 등록합니다.
+// 등록한 순서대로 실행됩니다.
+func (s *Server) OnShutdown(f func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, f)
+}
+
+// OnShutdownStart: 종료가 시작되는 즉시(처리 중인 요청을 기다리기 전에) 별도 고루틴에서 실행할 함수를 등록합니다.
+// SSE 스트림처럼 스스로 끝나지 않는 요청을 끝내는 데 사용합니다. (엔진과 관계없이 동작)
+func (s *Server) OnShutdownStart(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.startHooks = append(s.startHooks, f)
+}
+
+// Run: 서버를 시작하고 SIGINT/SIGTERM을 받으면 우아하게 종료합니다.
+// 정상 종료 시 nil을 반환합니다.
+func (s *Server) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return s.RunContext(ctx)
+}
+
+// RunContext: ctx가 취소될 때까지 서버를 실행한 뒤 우아하게 종료합니다.
+func (s *Server) RunContext(ctx context.Context) error {
+	if err := s.Config.Validate(); err != nil {
+		return err
+	}
+	if s.Config.TLS.active() {
+		tlsConfig, err := s.Config.TLS.build()
+		if err != nil {
+			return err
+		}
+		s.HTTP.TLSConfig = tlsConfig
+		if s.Config.TLS.RedirectAddr != "" {
+			s.redirect = &http.Server{
+				Addr:              s.Config.TLS.RedirectAddr,
+				Handler:           redirectHandler(s.httpsAddr()),
+				ReadHeaderTimeout: s.Config.ReadHeaderTimeout,
+				IdleTimeout:       s.Config.IdleTimeout,
+				MaxHeaderBytes:    s.Config.MaxHeaderBytes,
+			}
+		}
+	}
+
+	// 미들웨어는 바깥부터 요청 수 집계 → 실제 클라이언트 IP → CORS → 속도 제한 → 동시 요청 제한 → 압축 → 핸들러 순서로 감쌉니다.
+	// (거절 응답에도 CORS 헤더가 붙어야 브라우저 앱이 429/503을 읽을 수 있음)
+	// CORS부터 압축까지는 Reload로 교체할 수 있도록 live에 보관합니다.
+	s.mu.Lock()
+	s.base = s.HTTP.Handler
+	h, err := s.buildChain(s.Config)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.live.Store(&h)
+	s.mu.Unlock()
+	s.HTTP.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		(*s.live.Load()).ServeHTTP(w, r)
+	})
+	if s.Config.TrustedProxies != "" {
+		// 실제 클라이언트 주소는 속도 제한과 접근 로그보다 먼저 정해야 하므로 가장 바깥에 둠
+		res, err := realip.ParseTrusted(s.Config.TrustedProxies)
+		if err != nil {
+			return err
+		}
+		s.proxies = res
+		s.HTTP.Handler = res.Middleware(s.HTTP.Handler)
+	}
+	s.HTTP.Handler = s.instrument(s.HTTP.Handler)
+
+	if s.Config.Admin.Addr != "" {
+		s.admin = &http.Server{
+			Addr:              s.Config.Admin.Addr,
+			Handler:           s.adminHandler(),
+			ReadHeaderTimeout: s.Config.ReadHeaderTimeout,
+			IdleTimeout:       s.Config.IdleTimeout,
+			MaxHeaderBytes:    s.Config.MaxHeaderBytes,
+			// WriteTimeout 없음: /debug/pprof/profile, trace는 요청한 시간 동안 응답을 씀
+		}
+	}
+
+	if s.Config.Engine == EngineRaw {
+		// HTTP에 적용된 설정(핸들러, 타임아웃, TLS)을 그대로 rawhttp 서버로 옮김
+		s.raw = &rawhttp.Server{
+			Addr:              s.HTTP.Addr,
+			Handler:           s.HTTP.Handler,
+			TLSConfig:         s.HTTP.TLSConfig,
+			ReadTimeout:       s.HTTP.ReadTimeout,
+			ReadHeaderTimeout: s.HTTP.ReadHeaderTimeout,
+			WriteTimeout:      s.HTTP.WriteTimeout,
+			IdleTimeout:       s.HTTP.IdleTimeout,
+			MaxHeaderBytes:    s.HTTP.MaxHeaderBytes,
+		}
+	}
+
+	servers := s.servers()
+	s.mu.Lock()
+	for _, f := range s.startHooks {
+		servers[0].RegisterOnShutdown(f)
+	}
+	s.mu.Unlock()
+
+	errCh := make(chan error, len(servers))
+	for _, srv := range servers {
+		go func() {
+			errCh <- srv.serve()
+		}()
+	}
+
+	select {
+	case err := <-errCh:
+		// 시작 실패 (포트 사용 중 등): 이미 시작된 다른 서버도 닫습니다.
+		for _, srv := range servers {
+			srv.Close()
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	return s.shutdown(servers, errCh)
+}
+
+// servers: 함께 실행/종료되는 서버 목록 (첫 번째가 주 서버, 리다이렉트·관리 서버는 항상 net/http)
+func (s *Server) servers() []runner {
+	// TLS 여부는 미리 정해 둠: net/http의 Serve는 HTTP/2 설정 중에 HTTP.TLSConfig를 채우므로
+	// 리스너마다 TLSConfig를 확인하면 나중에 시작한 리스너가 TLS로 잘못 시작될 수 있음
+	secure := s.HTTP.TLSConfig != nil
+	main := runner{engine: s.HTTP, addr: s.Config.Addr, serve: func() error {
+		return s.serveAll(func(l net.Listener) error {
+			if secure {
+				// 인증서는 TLSConfig.Certificates에 이미 들어 있습니다.
+				return s.HTTP.ServeTLS(l, "", "")
+			}
+			return s.HTTP.Serve(l)
+		})
+	}}
+	if s.raw != nil {
+		main = runner{engine: s.raw, addr: s.Config.Addr, serve: func() error {
+			return s.serveAll(func(l net.Listener) error {
+				if secure {
+					return s.raw.ServeTLS(l)
+				}
+				return s.raw.Serve(l)
+			})
+		}}
+	}
+	runners := []runner{main}
+	if s.redirect != nil {
+		runners = append(runners, runner{engine: s.redirect, addr: s.redirect.Addr, serve: s.redirect.ListenAndServe})
+	}
+	if s.admin != nil {
+		// 주 서버가 처리 중인 요청을 마무리하는 동안에도 /readyz(503)를 확인할 수 있도록 마지막에 종료
+		runners = append(runners, runner{engine: s.admin, addr: s.admin.Addr, serve: s.admin.ListenAndServe})
+	}
+	return runners
+}
+
+// serveAll: 주 서버의 리스너를 모두 열고 각각 serve로 요청을 받습니다.
+// 하나가 멈추면(종료 또는 Accept 오류) 그 결과를 반환하며, 나머지 리스너는 Shutdown/Close가 함께 닫습니다.
+func (s *Server) serveAll(serve func(net.Listener) error) error {
+	ls, err := s.listen()
+	if err != nil {
+		return err
+	}
+	errCh := make(chan error, len(ls))
+	for _, l := range ls {
+		go func() {
+			errCh <- serve(l)
+		}()
+	}
+	return <-errCh
+}
+
+// listen: 주 서버의 리스너 (TCP, Unix 소켓, systemd 소켓). PROXY 프로토콜을 쓰면 TLS보다 안쪽에서 헤더를 읽도록 감쌉니다.
+func (s *Server) listen() ([]net.Listener, error) {
+	addrs, err := listener.Parse(s.Config.Addr)
+	if err != nil {
+		return nil, err
+	}
+	mode, err := listener.ParseMode(s.Config.SocketMode)
+	if err != nil {
+		return nil, err
+	}
+	ls, err := listener.Listen(addrs, listener.SocketOptions{Mode: mode, Owner: s.Config.SocketOwner})
+	if err != nil {
+		return nil, err
+	}
+	if s.Config.ProxyProtocol {
+		for i, l := range ls {
+			ls[i] = &realip.ProxyListener{Listener: l, Resolver: s.proxies}
+		}
+	}
+	return ls, nil
+}
+
+// httpsAddr: HTTP→HTTPS 리다이렉트가 가리킬 주 서버의 TCP 주소 (TCP 주소가 없으면 "", 기본 포트 443으로 안내)
+func (s *Server) httpsAddr() string {
+	addrs, _ := listener.Parse(s.Config.Addr)
+	return listener.FirstTCP(addrs)
+}
+
+// shutdown: 새 연결 수락을 멈추고, 처리 중인 요청을 ShutdownTimeout까지 기다린 뒤 정리 작업을 실행합니다.
+func (s *Server) shutdown(servers []runner, errCh <-chan error) error {
+	log.Printf("## Shutdown signal received, draining in-flight requests (timeout %s).", s.Config.ShutdownTimeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.Config.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for _, srv := range servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			// 제한 시간 내에 끝나지 않은 연결은 강제로 닫습니다.
+			errs = append(errs, fmt.Errorf("graceful shutdown of %s: %w", srv.addr, err))
+			srv.Close()
+		}
+	}
+	for range servers {
+		if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+	}
+
+	// 정리 작업은 요청 대기 시간과 별도의 제한 시간으로 실행
+	hookCtx, hookCancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer hookCancel()
+
+	s.mu.Lock()
+	hooks := s.hooks
+	s.mu.Unlock()
+	for _, hook := range hooks {
+		if err := hook(hookCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
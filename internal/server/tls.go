@@ -0,0 +1,133 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"full_stack_service_networking_project/internal/tlsutil"
+)
+
+// TLSConfig: HTTPS 설정
+type TLSConfig struct {
+	Enabled      bool
+	CertFile     string // PEM 인증서 (체인 포함 가능)
+	KeyFile      string // PEM 개인키
+	Dev          bool   // 인증서가 없으면 DevDir에 자체 서명 CA와 서버 인증서를 생성
+	DevDir       string
+	DevHosts     string // 개발용 서버 인증서의 SAN (쉼표 구분)
+	MinVersion   string // 최소 TLS 버전 (1.2, 1.3)
+	CipherSuites string // TLS 1.2 이하에서 사용할 암호 스위트 선호 순서 (쉼표 구분)
+	RedirectAddr string // 지정하면 이 주소의 평문 HTTP 요청을 HTTPS로 리다이렉트
+	ClientCAFile string // 지정하면 mTLS: 이 CA가 서명한 클라이언트 인증서를 요구
+}
+
+func (c *TLSConfig) registerFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&c.Enabled, "tls", c.Enabled, "serve HTTPS instead of HTTP")
+	fs.StringVar(&c.CertFile, "tls-cert", c.CertFile, "TLS certificate file (PEM)")
+	fs.StringVar(&c.KeyFile, "tls-key", c.KeyFile, "TLS private key file (PEM)")
+	fs.BoolVar(&c.Dev, "tls-dev", c.Dev, "generate a self-signed dev CA and server certificate on first start (implies -tls)")
+	fs.StringVar(&c.DevDir, "tls-dev-dir", c.DevDir, "directory for generated dev certificates")
+	fs.StringVar(&c.DevHosts, "tls-dev-hosts", c.DevHosts, "comma-separated host names/IPs for the dev server certificate")
+	fs.StringVar(&c.MinVersion, "tls-min-version", c.MinVersion, "minimum TLS version: 1.2 or 1.3")
+	fs.StringVar(&c.CipherSuites, "tls-ciphers", c.CipherSuites, "comma-separated TLS 1.2 cipher suites in preference order (default: Go defaults)")
+	fs.StringVar(&c.RedirectAddr, "http-redirect-addr", c.RedirectAddr, "plain HTTP address that redirects to HTTPS, e.g. :8081 (TLS only)")
+	fs.StringVar(&c.ClientCAFile, "tls-client-ca", c.ClientCAFile, "require client certificates signed by this CA bundle (mTLS)")
+}
+
+// active: TLS를 사용하는지 여부
+func (c *TLSConfig) active() bool {
+	return c.Enabled || c.Dev
+}
+
+// validate: 개발용 인증서를 만들려면 인증서에 넣을 호스트 이름이 하나 이상 있어야 합니다.
+func (c *TLSConfig) validate() error {
+	if c.Dev && (c.CertFile == "" || c.KeyFile == "") && len(splitList(c.DevHosts)) == 0 {
+		return errors.New("-tls-dev requires at least one host in -tls-dev-hosts")
+	}
+	return nil
+}
+
+// build: 설정으로부터 tls.Config를 만듭니다.
+func (c *TLSConfig) build() (*tls.Config, error) {
+	certFile, keyFile := c.CertFile, c.KeyFile
+	if c.Dev && (certFile == "" || keyFile == "") {
+		files, err := tlsutil.EnsureDevCerts(c.DevDir, splitList(c.DevHosts))
+		if err != nil {
+			return nil, fmt.Errorf("prepare dev certificates: %w", err)
+		}
+		certFile, keyFile = files.ServerCert, files.ServerKey
+		log.Printf("## Using dev certificate %s (clients should trust %s).", certFile, files.CACert)
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("TLS requires -tls-cert and -tls-key, or -tls-dev")
+	}
+
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS key pair: %w", err)
+	}
+	minVersion, err := tlsutil.ParseVersion(c.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	ciphers, err := tlsutil.ParseCipherSuites(c.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		MinVersion:   minVersion,
+		CipherSuites: ciphers,
+	}
+	if c.ClientCAFile != "" {
+		// mTLS: 시스템 신뢰 저장소가 아닌 지정한 CA만 클라이언트 인증서 발급자로 인정
+		caPEM, err := os.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", c.ClientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// redirectHandler: 평문 HTTP 요청을 같은 호스트의 HTTPS 주소로 영구 리다이렉트합니다.
+func redirectHandler(httpsAddr string) http.Handler {
+	_, httpsPort, _ := net.SplitHostPort(httpsAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]" // IPv6
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host += ":" + httpsPort
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
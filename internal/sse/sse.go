@@ -0,0 +1,239 @@
+// Package sse는 Server-Sent Events(text/event-stream)로 서버의 이벤트를 브라우저에 실시간 전달합니다.
+// 최근 이벤트를 링 버퍼에 보관하여 재접속한 클라이언트가 Last-Event-ID 이후의 이벤트를 이어 받을 수 있고,
+// 발행(Publish)은 절대 블록되지 않으며 따라가지 못하는 느린 클라이언트는 연결을 끊습니다.
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 기본값
+const (
+	DefaultHistory      = 256              // 재전송용으로 보관하는 최근 이벤트 수
+	DefaultClientBuffer = 64               // 클라이언트별 대기 이벤트 수 (넘으면 연결 종료)
+	DefaultHeartbeat    = 15 * time.Second // 이벤트가 없을 때 주석 줄을 보내는 간격
+	DefaultWriteTimeout = 10 * time.Second // 이벤트 하나를 쓰는 제한 시간
+	DefaultRetry        = 3 * time.Second  // 브라우저 EventSource의 재접속 대기 시간
+)
+
+// Event: 스트림으로 보내는 이벤트 하나
+type Event struct {
+	ID   uint64
+	Type string // event: 필드 (비어 있으면 브라우저에서 "message")
+	Data []byte // data: 필드 (줄바꿈이 있으면 여러 data: 줄로 나뉨)
+}
+
+// subscriber: 연결된 클라이언트 하나
+type subscriber struct {
+	ch chan Event
+}
+
+// Broker: 이벤트를 발행하고 /events 요청을 처리하는 http.Handler
+type Broker struct {
+	ClientBuffer int
+	Heartbeat    time.Duration
+	WriteTimeout time.Duration
+
+	mu      sync.Mutex
+	history []Event // 링 버퍼
+	start   int
+	size    int
+	nextID  uint64
+	subs    map[*subscriber]struct{}
+	closed  bool
+}
+
+// NewBroker: 최근 history개의 이벤트를 보관하는 브로커를 만듭니다.
+func NewBroker(history int) *Broker {
+	if history < 1 {
+		history = 1
+	}
+	return &Broker{
+		ClientBuffer: DefaultClientBuffer,
+		Heartbeat:    DefaultHeartbeat,
+		WriteTimeout: DefaultWriteTimeout,
+		history:      make([]Event, history),
+		nextID:       1,
+		subs:         make(map[*subscriber]struct{}),
+	}
+}
+
+// Publish: data를 JSON으로 인코딩하여 eventType 이벤트로 발행합니다.
+// 잠금을 쥔 채로 호출해도 되도록 절대 블록되지 않습니다.
+func (b *Broker) Publish(eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+
+	ev := Event{ID: b.nextID, Type: eventType, Data: payload}
+	b.nextID++
+	b.history[(b.start+b.size)%len(b.history)] = ev
+	if b.size < len(b.history) {
+		b.size++
+	} else {
+		b.start = (b.start + 1) % len(b.history)
+	}
+
+	for sub := range b.subs {
+		select {
+		case sub.ch <- ev:
+		default:
+			// 느린 클라이언트: 기다리지 않고 연결을 끊음 (재접속하면 Last-Event-ID로 이어 받음)
+			close(sub.ch)
+			delete(b.subs, sub)
+		}
+	}
+	return nil
+}
+
+// subscribe: lastID 이후의 보관된 이벤트와 함께 새 구독을 등록합니다.
+func (b *Broker) subscribe(lastID uint64) (*subscriber, []Event, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, nil, false
+	}
+
+	var replay []Event
+	for i := 0; i < b.size; i++ {
+		ev := b.history[(b.start+i)%len(b.history)]
+		if ev.ID > lastID {
+			replay = append(replay, ev)
+		}
+	}
+	sub := &subscriber{ch: make(chan Event, b.ClientBuffer)}
+	b.subs[sub] = struct{}{}
+	return sub, replay, true
+}
+
+// unsubscribe: 구독을 해제합니다. (이미 끊긴 경우 아무것도 하지 않음)
+func (b *Broker) unsubscribe(sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Close: 모든 스트림을 끝냅니다. 스트림은 끝나지 않는 요청이므로, 서버의 우아한 종료가
+// 이를 기다리지 않도록 http.Server.RegisterOnShutdown에 등록해 사용합니다.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Clients: 연결된 클라이언트 수
+func (b *Broker) Clients() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// lastEventID: 재접속 시 브라우저가 보내는 Last-Event-ID 헤더 (또는 ?lastEventId= 파라미터)
+func lastEventID(r *http.Request) uint64 {
+	s := r.Header.Get("Last-Event-ID")
+	if s == "" {
+		s = r.URL.Query().Get("lastEventId")
+	}
+	id, _ := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	return id
+}
+
+// writeEvent: 이벤트 하나를 text/event-stream 형식으로 씁니다.
+func writeEvent(w http.ResponseWriter, ev Event) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "id: %d\n", ev.ID)
+	if ev.Type != "" {
+		fmt.Fprintf(&sb, "event: %s\n", ev.Type)
+	}
+	for _, line := range strings.Split(string(ev.Data), "\n") {
+		fmt.Fprintf(&sb, "data: %s\n", line)
+	}
+	sb.WriteString("\n")
+	_, err := w.Write([]byte(sb.String()))
+	return err
+}
+
+// ServeHTTP: 이벤트 스트림을 보냅니다. Last-Event-ID가 있으면 그 이후의 보관된 이벤트부터 보냅니다.
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method not supported. Use GET.", http.StatusMethodNotAllowed)
+		return
+	}
+	rc := http.NewResponseController(w)
+
+	sub, replay, ok := b.subscribe(lastEventID(r))
+	if !ok {
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer b.unsubscribe(sub)
+
+	// write는 제한 시간 안에 쓰고 바로 전송합니다. (서버의 WriteTimeout 대신 쓰기마다 기한을 연장)
+	write := func(fn func() error) error {
+		rc.SetWriteDeadline(time.Now().Add(b.WriteTimeout))
+		if err := fn(); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // 리버스 프록시의 버퍼링 방지
+	err := write(func() error {
+		w.WriteHeader(http.StatusOK)
+		_, err := fmt.Fprintf(w, "retry: %d\n\n", DefaultRetry.Milliseconds())
+		return err
+	})
+	for _, ev := range replay {
+		if err != nil {
+			return
+		}
+		err = write(func() error { return writeEvent(w, ev) })
+	}
+	if err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(b.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case ev, ok := <-sub.ch:
+			if !ok {
+				return // 버퍼 초과로 끊겼거나 서버 종료
+			}
+			if err := write(func() error { return writeEvent(w, ev) }); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			// 주석 줄: 프록시의 유휴 연결 종료를 막고 끊긴 연결을 발견
+			if err := write(func() error { _, err := fmt.Fprint(w, ": heartbeat\n\n"); return err }); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DevFiles: 개발 모드에서 생성/재사용하는 파일 경로
+type DevFiles struct {
+	CACert, CAKey         string
+	ServerCert, ServerKey string
+}
+
+// DevPaths: dir 아래의 개발용 인증서 파일 경로
+func DevPaths(dir string) DevFiles {
+	return DevFiles{
+		CACert:     filepath.Join(dir, "ca.pem"),
+		CAKey:      filepath.Join(dir, "ca-key.pem"),
+		ServerCert: filepath.Join(dir, "server.pem"),
+		ServerKey:  filepath.Join(dir, "server-key.pem"),
+	}
+}
+
+// EnsureDevCerts: dir에 개발용 CA와 서버 인증서가 없으면 생성하고, 있으면 그대로 사용합니다.
+// 클라이언트는 ca.pem을 신뢰할 CA로 지정하면 됩니다.
+func EnsureDevCerts(dir string, hosts []string) (DevFiles, error) {
+	files := DevPaths(dir)
+	if len(hosts) == 0 {
+		return files, errors.New("dev certificate needs at least one host name")
+	}
+	if err := verifyDevCerts(files); err == nil {
+		return files, nil
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return files, err
+	}
+
+	var ca *CA
+	if fileExists(files.CACert) && fileExists(files.CAKey) {
+		loaded, err := LoadCA(files.CACert, files.CAKey)
+		if err != nil {
+			return files, err
+		}
+		ca = loaded
+	} else {
+		created, caKeyPEM, err := NewCA("Dev Root CA", 10*365*24*time.Hour)
+		if err != nil {
+			return files, err
+		}
+		if err := WriteFiles(files.CACert, created.CertPEM, files.CAKey, caKeyPEM); err != nil {
+			return files, err
+		}
+		ca = created
+	}
+
+	certPEM, keyPEM, err := ca.Issue(IssueOptions{
+		CommonName: hosts[0],
+		Hosts:      hosts,
+		Usage:      ServerAuth,
+		Validity:   365 * 24 * time.Hour,
+	})
+	if err != nil {
+		return files, err
+	}
+	return files, WriteFiles(files.ServerCert, certPEM, files.ServerKey, keyPEM)
+}
+
+// verifyDevCerts: 기존 서버 인증서가 CA로 검증되고 만료되지 않았는지 확인합니다.
+// 실패하면 서버 인증서를 다시 발급해야 합니다.
+func verifyDevCerts(files DevFiles) error {
+	pair, err := tls.LoadX509KeyPair(files.ServerCert, files.ServerKey)
+	if err != nil {
+		return err
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return err
+	}
+	caPEM, err := os.ReadFile(files.CACert)
+	if err != nil {
+		return err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return fmt.Errorf("no certificates found in %s", files.CACert)
+	}
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: roots})
+	return err
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// LoadCertPool: 시스템 신뢰 저장소에 PEM CA 묶음(bundle)을 추가한 인증서 풀을 반환합니다.
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA bundle: %w", err)
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return pool, nil
+}
+
+// ClientConfig: 클라이언트용 TLS 설정 (caFile이 비어 있으면 시스템 신뢰 저장소만 사용)
+func ClientConfig(caFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pool, err := LoadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// ParseVersion: "1.2", "1.3" 형식의 TLS 버전을 변환합니다.
+func ParseVersion(s string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(s), "tls") {
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12", "":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unknown TLS version %q (expected 1.0, 1.1, 1.2 or 1.3)", s)
+}
+
+// ParseCipherSuites: 쉼표로 구분된 암호 스위트 이름 목록을 ID 목록으로 변환합니다. (선호 순서 유지)
+// 안전하지 않은 스위트는 거부합니다. TLS 1.3 스위트는 Go가 자동으로 선택하므로 지정할 수 없습니다.
+func ParseCipherSuites(list string) ([]uint16, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	byName := make(map[string]uint16)
+	for _, cs := range tls.CipherSuites() {
+		byName[cs.Name] = cs.ID
+	}
+
+	var ids []uint16
+	var errs []error
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		id, ok := byName[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown or insecure cipher suite %q", name))
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, errors.Join(errs...)
+}
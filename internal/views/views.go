@@ -0,0 +1,25 @@
+// Package views는 계산기 서버의 HTML 페이지 템플릿을 바이너리에 포함(embed)하여 제공합니다.
+// 모든 페이지는 공통 레이아웃(templates/layout.html)을 쓰며, 페이지 파일은 "title"과 "content" 블록을 정의합니다.
+// html/template이 문맥(본문, 속성, URL)에 맞게 값을 이스케이프하므로 요청 경로나 오류 메시지를 넣어도 안전합니다.
+package views
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+)
+
+//go:embed templates/*.html
+var files embed.FS
+
+// funcs: 페이지 템플릿에서 쓰는 함수
+var funcs = template.FuncMap{
+	"statusText": http.StatusText, // {{statusText .Status}} → "Not Found"
+}
+
+// Page: 레이아웃과 templates/<name>.html을 함께 파싱한 템플릿. Execute하면 완성된 페이지를 출력합니다.
+// 파일이 없거나 문법 오류가 있으면 panic하므로 패키지 변수 초기화에 사용합니다. (template.Must와 같음)
+func Page(name string) *template.Template {
+	return template.Must(template.New("layout.html").Funcs(funcs).
+		ParseFS(files, "templates/layout.html", "templates/"+name+".html"))
+}
@@ -0,0 +1,417 @@
+// Package websocket은 표준 라이브러리만으로 RFC 6455 WebSocket 프로토콜을 구현합니다.
+// 서버는 net/http의 Hijacker로 연결을 넘겨받고(Upgrade), 클라이언트는 직접 TCP/TLS 연결을 엽니다(Dial).
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MessageType: 데이터 메시지 종류
+type MessageType int
+
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+)
+
+// 프레임 opcode (RFC 6455 5.2)
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// 종료 코드 (RFC 6455 7.4.1)
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005 // 프레임에 코드가 없을 때 수신 측이 사용 (전송 금지)
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseTooBig          = 1009
+	CloseInternalError   = 1011
+)
+
+// 기본값
+const (
+	DefaultMaxMessageSize = 1 << 20  // 수신 메시지 최대 크기
+	DefaultFragmentSize   = 16 << 10 // 이보다 큰 메시지는 여러 프레임으로 나누어 전송
+	DefaultCloseTimeout   = 5 * time.Second
+	DefaultWriteTimeout   = 10 * time.Second
+	maxControlPayload     = 125
+)
+
+// noDeadline: 기한 해제용 0 값
+var noDeadline time.Time
+
+// ErrClosed: 종료 프레임을 보낸 뒤 데이터를 쓰려고 할 때의 오류
+var ErrClosed = errors.New("websocket: close sent")
+
+// CloseError: 상대방이 보낸 종료 프레임 또는 프로토콜 오류로 인한 종료
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket: close %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: close %d (%s)", e.Code, e.Reason)
+}
+
+// Conn: WebSocket 연결
+// ReadMessage는 한 고루틴에서만 호출해야 하며, 쓰기 메서드는 여러 고루틴에서 동시에 호출할 수 있습니다.
+type Conn struct {
+	MaxMessageSize int64             // 수신 메시지 최대 크기 (넘으면 1009로 종료)
+	FragmentSize   int               // 전송 프레임 최대 크기 (0이면 나누지 않음)
+	CloseTimeout   time.Duration     // 종료 프레임을 보낸 뒤 상대의 응답을 기다리는 시간 (종료 프레임을 쓰는 기한이기도 함)
+	WriteTimeout   time.Duration     // 프레임 하나를 쓰는 기한 (0이면 제한 없음). 읽지 않는 상대 때문에 쓰기 잠금을 계속 쥐지 않도록 함
+	OnPong         func(data []byte) // pong 프레임을 받았을 때 호출 (ReadMessage 고루틴에서)
+
+	conn     net.Conn
+	br       *bufio.Reader
+	isServer bool // 서버는 마스킹된 프레임만 받고, 클라이언트는 항상 마스킹하여 보냄
+
+	writeMu   sync.Mutex
+	closeSent bool
+}
+
+func newConn(conn net.Conn, br *bufio.Reader, isServer bool) *Conn {
+	if br == nil {
+		br = bufio.NewReader(conn)
+	}
+	return &Conn{
+		MaxMessageSize: DefaultMaxMessageSize,
+		FragmentSize:   DefaultFragmentSize,
+		CloseTimeout:   DefaultCloseTimeout,
+		WriteTimeout:   DefaultWriteTimeout,
+		conn:           conn,
+		br:             br,
+		isServer:       isServer,
+	}
+}
+
+// RemoteAddr: 상대방 주소
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetReadDeadline: 다음 프레임을 읽는 기한 (ping/pong 기반 연결 확인에 사용)
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// Close: 하위 연결을 닫습니다. 정상 종료 절차는 WriteClose를 사용합니다.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// frame: 수신한 프레임 하나
+type frame struct {
+	fin     bool
+	op      byte
+	payload []byte
+}
+
+// readFrame: 프레임 하나를 읽고 마스킹을 해제합니다.
+func (c *Conn) readFrame(remaining int64) (frame, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return frame{}, err
+	}
+	f := frame{fin: head[0]&0x80 != 0, op: head[0] & 0x0F}
+	if head[0]&0x70 != 0 {
+		return f, &CloseError{CloseProtocolError, "reserved bits set"}
+	}
+	masked := head[1]&0x80 != 0
+	if masked != c.isServer {
+		if c.isServer {
+			return f, &CloseError{CloseProtocolError, "client frame not masked"}
+		}
+		return f, &CloseError{CloseProtocolError, "server frame masked"}
+	}
+
+	length := int64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return f, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return f, err
+		}
+		n := binary.BigEndian.Uint64(ext[:])
+		if n>>63 != 0 {
+			return f, &CloseError{CloseProtocolError, "invalid payload length"}
+		}
+		length = int64(n)
+	}
+
+	if f.op >= opClose {
+		// 제어 프레임은 나눌 수 없고 125바이트 이하
+		if !f.fin {
+			return f, &CloseError{CloseProtocolError, "fragmented control frame"}
+		}
+		if length > maxControlPayload {
+			return f, &CloseError{CloseProtocolError, "control frame too large"}
+		}
+	} else if length > remaining {
+		return f, &CloseError{CloseTooBig, fmt.Sprintf("message exceeds %d bytes", c.MaxMessageSize)}
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return f, err
+		}
+	}
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, f.payload); err != nil {
+		return f, err
+	}
+	if masked {
+		maskBytes(mask, f.payload)
+	}
+	return f, nil
+}
+
+// maskBytes: 마스킹 키로 XOR (마스킹과 해제가 같은 연산)
+func maskBytes(key [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= key[i%4]
+	}
+}
+
+// ReadMessage: 데이터 메시지 하나를 읽습니다. 나뉜 프레임은 하나로 합치고,
+// 사이에 끼어든 ping에는 pong으로 응답합니다.
+// 상대방이 종료 프레임을 보내면 응답 후 *CloseError를 반환하며,
+// 프로토콜 위반을 발견하면 해당 코드로 종료 프레임을 보내고 *CloseError를 반환합니다.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	var (
+		msgType MessageType
+		msg     []byte
+		started bool
+	)
+	for {
+		f, err := c.readFrame(c.MaxMessageSize - int64(len(msg)))
+		if err != nil {
+			return 0, nil, c.fail(err)
+		}
+
+		switch f.op {
+		case opPing:
+			if err := c.writeFrame(true, opPong, f.payload); err != nil && !errors.Is(err, ErrClosed) {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			if c.OnPong != nil {
+				c.OnPong(f.payload)
+			}
+			continue
+		case opClose:
+			return 0, nil, c.handleClose(f.payload)
+		case opText, opBinary:
+			if started {
+				return 0, nil, c.fail(&CloseError{CloseProtocolError, "new message before previous one finished"})
+			}
+			started, msgType = true, MessageType(f.op)
+		case opContinuation:
+			if !started {
+				return 0, nil, c.fail(&CloseError{CloseProtocolError, "continuation without a message"})
+			}
+		default:
+			return 0, nil, c.fail(&CloseError{CloseProtocolError, fmt.Sprintf("unknown opcode %#x", f.op)})
+		}
+
+		msg = append(msg, f.payload...)
+		if !f.fin {
+			continue
+		}
+		if msgType == TextMessage && !utf8.Valid(msg) {
+			return 0, nil, c.fail(&CloseError{CloseInvalidPayload, "invalid UTF-8 in text message"})
+		}
+		return msgType, msg, nil
+	}
+}
+
+// handleClose: 받은 종료 프레임을 해석하고, 아직 보내지 않았다면 같은 코드로 응답합니다.
+func (c *Conn) handleClose(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatus}
+	switch {
+	case len(payload) == 1:
+		return c.fail(&CloseError{CloseProtocolError, "invalid close payload"})
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+		if !validCloseCode(closeErr.Code) {
+			return c.fail(&CloseError{CloseProtocolError, "invalid close code"})
+		}
+		if !utf8.ValidString(closeErr.Reason) {
+			return c.fail(&CloseError{CloseInvalidPayload, "invalid UTF-8 in close reason"})
+		}
+	}
+
+	code := closeErr.Code
+	if code == CloseNoStatus {
+		code = CloseNormal
+	}
+	c.WriteClose(code, "")
+	return closeErr
+}
+
+// validCloseCode: 프레임에 실어 보낼 수 있는 종료 코드인지 확인합니다.
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011:
+		return true
+	case code >= 3000 && code <= 4999: // 라이브러리/애플리케이션 정의
+		return true
+	}
+	return false
+}
+
+// fail: 프로토콜 오류이면 종료 프레임을 보내고, 오류를 그대로 반환합니다.
+func (c *Conn) fail(err error) error {
+	var closeErr *CloseError
+	if errors.As(err, &closeErr) {
+		c.WriteClose(closeErr.Code, closeErr.Reason)
+	}
+	return err
+}
+
+// WriteMessage: 데이터 메시지를 보냅니다. FragmentSize보다 크면 연속 프레임으로 나누어 보냅니다.
+func (c *Conn) WriteMessage(mt MessageType, data []byte) error {
+	if mt != TextMessage && mt != BinaryMessage {
+		return fmt.Errorf("websocket: invalid message type %d", mt)
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	op := byte(mt)
+	for {
+		chunk := data
+		if c.FragmentSize > 0 && len(chunk) > c.FragmentSize {
+			chunk = data[:c.FragmentSize]
+		}
+		data = data[len(chunk):]
+		if err := c.writeFrameLocked(len(data) == 0, op, chunk); err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			return nil
+		}
+		op = opContinuation
+	}
+}
+
+// WritePing: ping 프레임을 보냅니다. (data는 125바이트 이하)
+func (c *Conn) WritePing(data []byte) error {
+	return c.writeFrame(true, opPing, data)
+}
+
+// WriteClose: 종료 프레임을 한 번만 보내고, 상대의 응답을 CloseTimeout까지만 기다리도록 읽기 기한을 설정합니다.
+// 상대의 종료 프레임은 ReadMessage가 *CloseError로 반환합니다.
+func (c *Conn) WriteClose(code int, reason string) error {
+	if len(reason) > maxControlPayload-2 {
+		reason = reason[:maxControlPayload-2]
+	}
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	payload = append(payload, reason...)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return nil
+	}
+	err := c.writeFrameLocked(true, opClose, payload)
+	c.closeSent = true
+	c.conn.SetReadDeadline(time.Now().Add(c.CloseTimeout))
+	return err
+}
+
+func (c *Conn) writeFrame(fin bool, op byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.writeFrameLocked(fin, op, payload)
+}
+
+// writeFrameLocked: 프레임 하나를 씁니다. 클라이언트는 임의의 키로 마스킹합니다. (writeMu 잠금 상태에서 호출)
+func (c *Conn) writeFrameLocked(fin bool, op byte, payload []byte) error {
+	if c.closeSent {
+		return ErrClosed
+	}
+	if op >= opClose && len(payload) > maxControlPayload {
+		return fmt.Errorf("websocket: control frame payload exceeds %d bytes", maxControlPayload)
+	}
+
+	buf := make([]byte, 0, 14+len(payload))
+	b0 := op
+	if fin {
+		b0 |= 0x80
+	}
+	buf = append(buf, b0)
+
+	var maskBit byte
+	if !c.isServer {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		buf = append(buf, maskBit|byte(n))
+	case n <= 0xFFFF:
+		buf = append(buf, maskBit|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, maskBit|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+
+	if c.isServer {
+		buf = append(buf, payload...)
+	} else {
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		buf = append(buf, key[:]...)
+		start := len(buf)
+		buf = append(buf, payload...)
+		maskBytes(key, buf[start:])
+	}
+
+	timeout := c.WriteTimeout
+	if op == opClose {
+		timeout = c.CloseTimeout
+	}
+	if timeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(timeout))
+		defer c.conn.SetWriteDeadline(noDeadline)
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		// 프레임 일부만 나갔을 수 있으므로 더는 쓰지 않고 연결을 닫음 (ReadMessage도 오류로 끝남)
+		c.closeSent = true
+		c.conn.Close()
+		return err
+	}
+	return nil
+}
@@ -1,21 +1,33 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"os/signal"
 	"strings"
+	"time"
+
+	"full_stack_service_networking_project/internal/config"
+	"full_stack_service_networking_project/internal/httpclient"
+	"full_stack_service_networking_project/internal/websocket"
 )
 
+// httpClient: 모든 요청에 사용하는 HTTP 클라이언트 (main에서 -cacert 설정을 반영하여 생성)
+var httpClient = &http.Client{}
+
 // performRequest 함수: HTTP 요청을 수행하고 응답을 출력합니다.
 func performRequest(method, urlStr, responseLabel string, body io.Reader) {
 	fmt.Printf("## %s request for %s\n", method, urlStr)
 
-	// HTTP 클라이언트 생성
-	client := &http.Client{}
-
 	// 요청 객체 생성
 	req, err := http.NewRequest(method, urlStr, body)
 	if err != nil {
@@ -23,7 +35,7 @@ func performRequest(method, urlStr, responseLabel string, body io.Reader) {
 	}
 
 	// 요청 수행
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		// 서버가 실행되고 있지 않다면 연결 오류가 발생할 수 있습니다.
 		log.Printf("Error sending %s request to %s: %v", method, urlStr, err)
@@ -43,10 +55,118 @@ func performRequest(method, urlStr, responseLabel string, body io.Reader) {
 	fmt.Printf("## %s response [end]\n\n", responseLabel)
 }
 
+// runWebSocket: 서버의 /ws에 연결하여 표준 입력의 각 줄(수식 또는 JSON 객체)을 보내고 결과를 출력합니다.
+// 입력이 끝나거나(Ctrl-D) Ctrl-C를 누르면 종료 프레임을 보내고 서버의 응답을 기다린 뒤 끝냅니다.
+func runWebSocket(serverURL string, opts httpclient.Options) {
+	wsURL := strings.TrimSuffix(serverURL, "/") + "/ws"
+	fmt.Printf("## WebSocket connecting to %s\n", wsURL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	var conn *websocket.Conn
+	var err error
+	if opts.UnixSocket != "" {
+		// Unix 소켓 서버: 소켓에 직접 연결한 뒤 그 연결에서 핸드셰이크
+		var c net.Conn
+		if c, err = httpclient.DialContext(ctx, opts, ""); err == nil {
+			conn, err = websocket.NewClient(ctx, c, wsURL)
+		}
+	} else {
+		var tlsConfig *tls.Config
+		if tlsConfig, err = httpclient.TLSConfig(opts); err != nil {
+			log.Fatalf("Error configuring TLS: %v", err)
+		}
+		conn, err = websocket.Dial(ctx, wsURL, tlsConfig)
+	}
+	cancel()
+	if err != nil {
+		log.Fatalf("Error connecting WebSocket: %v", err)
+	}
+	defer conn.Close()
+	fmt.Println("## WebSocket connected. Enter an expression (e.g. 2^10) or a JSON object (e.g. {\"var1\":9,\"var2\":9}) per line.")
+
+	// 수신 고루틴: 결과를 출력하고, 연결이 닫히면 done을 닫음
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				fmt.Printf("## WebSocket closed: %v\n", err)
+				return
+			}
+			fmt.Printf("## WebSocket response => %s\n", msg)
+		}
+	}()
+
+	// 송신: 표준 입력을 한 줄씩 전송
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	interrupt, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				// 입력 끝: 정상 종료 절차
+				conn.WriteClose(websocket.CloseNormal, "")
+				<-done
+				return
+			}
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(line)); err != nil {
+				log.Printf("Error sending WebSocket message: %v", err)
+				<-done
+				return
+			}
+		case <-interrupt.Done():
+			conn.WriteClose(websocket.CloseNormal, "")
+			<-done
+			return
+		case <-done:
+			return
+		}
+	}
+}
+
 func main() {
+	// 서버 주소와 신뢰할 CA (HTTPS 개발 모드에서는 서버가 생성한 certs/ca.pem 지정)
+	serverFlag := flag.String("server", "http://localhost:8080", "server base URL (use https://localhost:8080 for TLS, or unix:///path/to.sock for a server listening on a Unix socket)")
+	caFile := flag.String("cacert", "", "PEM CA bundle to trust in addition to the system roots")
+	wsMode := flag.Bool("ws", false, "open an interactive WebSocket calculator session (reads expressions from stdin)")
+	// 설정 순서: 기본값 → -config JSON 파일 → CALC_CLIENT_* 환경 변수 → 명령행 플래그
+	loader := config.New("CALC_CLIENT", flag.CommandLine)
+	if err := loader.Load(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+	target, err := httpclient.ParseTarget("server", *serverFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	clientOpts := httpclient.Options{CAFile: *caFile, UnixSocket: target.UnixSocket}
+	if *wsMode {
+		runWebSocket(target.BaseURL, clientOpts)
+		return
+	}
+
+	client, err := httpclient.New(clientOpts)
+	if err != nil {
+		log.Fatalf("Error configuring HTTP client: %v", err)
+	}
+	httpClient = client
+
 	fmt.Println("## HTTP client started.")
 
-	serverURL := "http://localhost:8080"
+	serverURL := target.BaseURL
 
 	// --- 1. GET request for directory retrieval ---
 	// 파이썬: requests.get('http://localhost:8080/temp/')
@@ -80,8 +200,7 @@ func main() {
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
 	// POST 요청 수행 및 응답 처리
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		log.Printf("Error sending POST request: %v", err)
 		// 서버가 실행되고 있지 않다면 종료하지 않고 메시지만 출력
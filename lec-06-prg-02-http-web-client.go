@@ -1,79 +1,374 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"html/template"
 	"io"
 	"log"
+	"math"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"full_stack_service_networking_project/internal/accesslog"
+	"full_stack_service_networking_project/internal/batch"
+	"full_stack_service_networking_project/internal/binder"
+	"full_stack_service_networking_project/internal/calc"
+	"full_stack_service_networking_project/internal/config"
+	"full_stack_service_networking_project/internal/fileserver"
+	"full_stack_service_networking_project/internal/history"
+	"full_stack_service_networking_project/internal/metrics"
+	"full_stack_service_networking_project/internal/negotiate"
+	"full_stack_service_networking_project/internal/reqbody"
+	"full_stack_service_networking_project/internal/server"
+	"full_stack_service_networking_project/internal/sse"
+	"full_stack_service_networking_project/internal/views"
+	"full_stack_service_networking_project/internal/websocket"
 )
 
+// maxExprLength: expr 파라미터로 받을 수 있는 수식의 최대 길이
+const maxExprLength = 1024
+
+// 계산 모드 (mode 파라미터)
+const (
+	modeInt = "int" // 기본값: int 연산, 오버플로 시 오류
+	modeBig = "big" // 정밀도 모드: math/big 기반의 정확한 연산
+)
+
+// fileServer: 디렉토리 검색 GET 요청을 처리하는 정적 파일 서버 (main에서 -docroot로 초기화)
+var fileServer *fileserver.FileServer
+
+// bigLimits: 정밀도 모드의 피연산자/결과 크기 제한 (DoS 방지)
+var bigLimits = calc.DefaultBigLimits
+
+var errIntOverflow = errors.New("Integer overflow in int mode. Use mode=big for arbitrary precision.")
+
 // simpleCalc: 곱셈 함수 (파이썬의 simple_calc)
-func simpleCalc(para1, para2 int) int {
-	return para1 * para2
+// int 범위를 벗어나면 잘못된 값 대신 errIntOverflow를 반환합니다.
+func simpleCalc(para1, para2 int) (int, error) {
+	result := para1 * para2
+	if para1 != 0 && (result/para1 != para2 || (para1 == -1 && para2 == math.MinInt)) {
+		return 0, errIntOverflow
+	}
+	return result, nil
+}
+
+// bigCalc: 정밀도 모드의 곱셈 (var1, var2는 정수, 유리수 "1/3", 소수 "1.5" 모두 허용)
+func bigCalc(var1Str, var2Str string) (string, error) {
+	var1, err := calc.ParseBigOperand(var1Str, bigLimits)
+	if err != nil {
+		return "", fmt.Errorf("var1: %w", err)
+	}
+	var2, err := calc.ParseBigOperand(var2Str, bigLimits)
+	if err != nil {
+		return "", fmt.Errorf("var2: %w", err)
+	}
+	result, err := calc.MulBig(var1, var2, bigLimits)
+	if err != nil {
+		return "", err
+	}
+	return result.Format(bigLimits), nil
+}
+
+// exprCalc: expr 파라미터로 전달된 수식을 계산 (예: expr=(1+2)*max(3,4)^2)
+// 구문 오류는 문자 위치를 포함한 메시지로 반환됩니다.
+func exprCalc(expr, mode string) (string, error) {
+	if len(expr) > maxExprLength {
+		return "", fmt.Errorf("expression is too long (%d bytes, max %d)", len(expr), maxExprLength)
+	}
+	if mode == modeBig {
+		result, err := calc.EvaluateBig(expr, bigLimits)
+		if err != nil {
+			return "", err
+		}
+		return result.Format(bigLimits), nil
+	}
+	result, err := calc.Evaluate(expr)
+	if err != nil {
+		return "", err
+	}
+	return calc.FormatFloat(result), nil
+}
+
+// calcResult: 계산 결과 (HTML, JSON, 텍스트 응답에 공통으로 사용)
+// JSON 예: {"var1":9,"var2":9,"op":"*","result":81}
+type calcResult struct {
+	Method string `json:"-"`
+	Var1   any    `json:"var1,omitempty"`
+	Var2   any    `json:"var2,omitempty"`
+	Expr   string `json:"expr,omitempty"`
+	Op     string `json:"op,omitempty"`
+	Mode   string `json:"mode,omitempty"`
+	Result any    `json:"result"`
+}
+
+// Desc: 응답에 표시할 계산식 ("9 x 9" 또는 수식)
+func (c *calcResult) Desc() string {
+	if c.Expr != "" {
+		return c.Expr
+	}
+	return fmt.Sprintf("%v x %v", c.Var1, c.Var2)
+}
+
+// jsonNumber: JSON 숫자로 표현 가능한 값은 json.Number로, 그렇지 않은 값(예: 유리수 "7/6")은 문자열로 반환합니다.
+func jsonNumber(s string) any {
+	var n json.Number
+	if err := json.Unmarshal([]byte(s), &n); err == nil {
+		return n
+	}
+	return s
+}
+
+// calcParams: 계산 요청의 공통 파라미터 (GET 쿼리, POST 본문)
+type calcParams struct {
+	Mode string `param:"mode" default:"int" validate:"oneof=int|big"`
+	Expr string `param:"expr"`
+}
+
+// intOperands: int 모드의 피연산자
+type intOperands struct {
+	Var1 int `param:"var1" validate:"required"`
+	Var2 int `param:"var2" validate:"required"`
+}
+
+// bigOperands: 정밀도 모드의 피연산자 (정수, 유리수 "1/3", 소수 "1.5" 허용)
+type bigOperands struct {
+	Var1 string `param:"var1" validate:"required"`
+	Var2 string `param:"var2" validate:"required"`
 }
 
-// parameterRetrieval: 파라미터 검색 함수 (파이썬의 parameter_retrieval)
-func parameterRetrieval(msg string) ([]int, error) {
-	result := make([]int, 0, 2)
-	fields := strings.Split(msg, "&")
-	if len(fields) < 2 {
-		return nil, fmt.Errorf("Not enough parameters in message: %s", msg)
+// calculate: 쿼리 또는 폼 파라미터로 계산을 수행합니다. (GET, POST 공통)
+// 파라미터 오류는 잘못된 필드를 모두 나열한 *binder.ValidationError로 반환됩니다.
+func calculate(values url.Values) (*calcResult, error) {
+	var params calcParams
+	if err := binder.Bind(values, &params); err != nil {
+		return nil, err
+	}
+
+	res := &calcResult{}
+	if params.Mode == modeBig {
+		res.Mode = modeBig
 	}
 
-	for _, field := range fields {
-		parts := strings.Split(field, "=")
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("Invalid parameter format: %s", field)
+	// 값이 아닌 키로 판단: "?expr="를 var1/var2 계산으로 넘기지 않고 빈 수식 오류로 응답
+	if values.Has("expr") {
+		if strings.TrimSpace(params.Expr) == "" {
+			return nil, &binder.ValidationError{Fields: []*binder.FieldError{{Param: "expr", Msg: "must not be empty"}}}
 		}
-		// parts[0] is parameter name (e.g., "var1"), parts[1] is value
-		value, err := strconv.Atoi(parts[1])
+		res.Expr = params.Expr
+		result, err := exprCalc(res.Expr, params.Mode)
 		if err != nil {
-			return nil, fmt.Errorf("Parameter value is not an integer: %s", parts[1])
+			return nil, err
 		}
-		result = append(result, value)
+		res.Result = jsonNumber(result)
+		return res, nil
 	}
-	return result, nil
+
+	res.Op = "*"
+
+	if params.Mode == modeBig {
+		var ops bigOperands
+		if err := binder.Bind(values, &ops); err != nil {
+			return nil, err
+		}
+		result, err := bigCalc(ops.Var1, ops.Var2)
+		if err != nil {
+			return nil, err
+		}
+		res.Var1, res.Var2, res.Result = jsonNumber(ops.Var1), jsonNumber(ops.Var2), jsonNumber(result)
+		return res, nil
+	}
+
+	var ops intOperands
+	if err := binder.Bind(values, &ops); err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, errIntOverflow
+		}
+		return nil, err
+	}
+
+	product, err := simpleCalc(ops.Var1, ops.Var2)
+	if err != nil {
+		return nil, err
+	}
+	res.Var1, res.Var2, res.Result = ops.Var1, ops.Var2, product
+	return res, nil
 }
 
-// myHttpHandler: HTTP 요청을 처리하는 핸들러 함수
-func myHttpHandler(w http.ResponseWriter, r *http.Request) {
-	// 요청 상세 정보 출력 (파이썬의 print_http_request_detail)
-	// r.RemoteAddr은 'IP:Port' 형식입니다.
-	var clientIP, clientPort string
-	host, port, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		// IPv6 또는 포맷이 다를 경우 전체 RemoteAddr을 IP로 사용
-		clientIP = r.RemoteAddr
-		clientPort = ""
-	} else {
-		clientIP = host
-		clientPort = port
+// =================================================================
+// 응답 형식 협상 (HTML / JSON / 텍스트)
+// =================================================================
+
+// HTML 페이지 템플릿 (internal/views/templates, 공통 레이아웃 사용)
+var (
+	calcTemplate  = views.Page("calc")
+	errorTemplate = views.Page("error")
+	dirTemplate   = views.Page("dir")
+	formTemplate  = views.Page("form")
+)
+
+// errorResponse: 오류 응답 데이터
+type errorResponse struct {
+	Status  int    `json:"status"`
+	Message string `json:"error"`
+}
+
+// dirListing: 디렉토리 목록 응답 데이터
+type dirListing struct {
+	Path    string             `json:"path"`
+	Entries []fileserver.Entry `json:"entries"`
+}
+
+// respond: 협상된 형식으로 응답을 작성합니다.
+// HTML은 tmpl, JSON은 data, 텍스트는 text를 사용합니다.
+func respond(w http.ResponseWriter, r *http.Request, status int, tmpl *template.Template, data any, text string) {
+	format, err := negotiate.FromRequest(r)
+	if err != nil && status >= 400 {
+		// 오류 응답(429/503 거절 등)은 상태 코드를 유지하고 텍스트로 보냄 (406으로 바꾸면 원래 오류가 가려짐)
+		negotiate.WriteText(w, status, text)
+		return
+	}
+	if err != nil {
+		// 형식을 정할 수 없으므로 텍스트로 응답
+		code := http.StatusBadRequest
+		if errors.Is(err, negotiate.ErrNotAcceptable) {
+			code = http.StatusNotAcceptable
+		}
+		negotiate.WriteText(w, code, "Error: "+err.Error())
+		return
+	}
+
+	switch format {
+	case negotiate.JSON:
+		err = negotiate.WriteJSON(w, status, data)
+	case negotiate.Text:
+		err = negotiate.WriteText(w, status, text)
+	default:
+		err = negotiate.WriteHTML(w, status, tmpl, data)
 	}
+	if err != nil {
+		log.Printf("Error writing %s response: %v", format, err)
+	}
+}
+
+// respondError: 오류를 협상된 형식으로 응답합니다.
+func respondError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	respond(w, r, status, errorTemplate, errorResponse{Status: status, Message: msg}, "Error: "+msg)
+}
+
+// respondCalc: 계산 결과를 협상된 형식으로 응답합니다.
+func respondCalc(w http.ResponseWriter, r *http.Request, res *calcResult) {
+	text := fmt.Sprintf("%s request for calculation => %s = %v", res.Method, res.Desc(), res.Result)
+	respond(w, r, http.StatusOK, calcTemplate, res, text)
+}
+
+// renderListing: 파일 서버의 디렉토리 목록을 협상된 형식으로 응답합니다.
+func renderListing(w http.ResponseWriter, r *http.Request, urlPath string, entries []fileserver.Entry) {
+	var text strings.Builder
+	for _, e := range entries {
+		text.WriteString(e.DisplayName() + "\n")
+	}
+	respond(w, r, http.StatusOK, dirTemplate, dirListing{Path: urlPath, Entries: entries}, strings.TrimSuffix(text.String(), "\n"))
+}
 
-	fmt.Println("::Client address   : ", clientIP)
-	fmt.Println("::Client port      : ", clientPort)
-	fmt.Println("::Request command  : ", r.Method)
-	fmt.Println("::Request line     : ", r.Proto+" "+r.URL.String())
-	fmt.Println("::Request path     : ", r.URL.Path)
-	fmt.Println("::Request version  : ", r.Proto)
+// myHttpHandler: HTTP 요청을 처리하는 핸들러 함수
+func myHttpHandler(w http.ResponseWriter, r *http.Request) {
+	// 요청 상세 정보 출력 (파이썬의 print_http_request_detail)은
+	// 접근 로그 미들웨어가 -log-level=debug일 때 수행합니다.
 
 	// 응답 헤더 설정 (파이썬의 send_http_response_header)
-	w.Header().Set("Content-Type", "text/html")
-	// 상태 코드는 핸들러 내부에서 필요 시 설정하도록 제거함
+	// Content-Type은 Accept 헤더 또는 ?format= 파라미터로 협상하여 respond()에서 설정합니다.
 
 	switch r.Method {
 	case "GET":
 		handleGet(w, r)
+	case "HEAD":
+		// GET과 같은 응답을 만들되 본문 없이 헤더와 Content-Length만 보냄
+		hw := &headWriter{ResponseWriter: w}
+		handleGet(hw, r)
+		hw.finish()
 	case "POST":
 		handlePost(w, r)
+	case "OPTIONS":
+		handleOptions(w, r)
+	case "PUT", "DELETE":
+		// 문서 루트의 파일 업로드/삭제 (-write-dirs로 허용한 디렉토리만, 권한 검사 훅 적용)
+		fileServer.ServeHTTP(w, r)
+		fmt.Printf("## %s request for file => %s.\n", r.Method, r.URL.Path)
 	default:
 		// 지원하지 않는 메서드에 대한 응답
-		http.Error(w, "Method not supported", http.StatusMethodNotAllowed)
+		w.Header().Set("Allow", allowedMethods())
+		respondError(w, r, http.StatusMethodNotAllowed, "Method not supported")
+	}
+}
+
+// allowedMethods: myHttpHandler가 처리하는 메서드 (Allow 헤더 값). PUT/DELETE는 쓰기가 허용된 경우에만 포함합니다.
+func allowedMethods() string {
+	methods := []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodOptions}
+	if fileServer.WritesEnabled() {
+		methods = append(methods, http.MethodPut, http.MethodDelete)
+	}
+	return strings.Join(methods, ", ")
+}
+
+// handleOptions: OPTIONS 요청 처리. Allow 헤더로 지원 메서드를 알립니다.
+// CORS 사전 요청(preflight)은 -cors-policy가 설정되면 이 핸들러에 오기 전에 CORS 미들웨어가 응답합니다.
+func handleOptions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Allow", allowedMethods())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// headWriter: HEAD 응답용 ResponseWriter. 본문은 버리고 길이만 세어,
+// 핸들러가 끝나면 GET 응답과 같은 Content-Length를 붙여 헤더를 보냅니다.
+type headWriter struct {
+	http.ResponseWriter
+	status  int
+	written int64
+}
+
+func (hw *headWriter) WriteHeader(code int) {
+	if hw.status == 0 {
+		hw.status = code
+	}
+}
+
+func (hw *headWriter) Write(p []byte) (int, error) {
+	if hw.status == 0 {
+		hw.status = http.StatusOK
+	}
+	hw.written += int64(len(p))
+	return len(p), nil
+}
+
+// Unwrap: http.ResponseController가 원래 ResponseWriter에 접근할 수 있도록 합니다.
+func (hw *headWriter) Unwrap() http.ResponseWriter {
+	return hw.ResponseWriter
+}
+
+// finish: 세어 둔 길이로 Content-Length를 정하고 헤더를 보냅니다. (http.ServeContent처럼 이미 정한 경우는 유지)
+func (hw *headWriter) finish() {
+	if hw.status == 0 {
+		hw.status = http.StatusOK
 	}
+	h := hw.Header()
+	bodyAllowed := hw.status >= 200 && hw.status != http.StatusNoContent && hw.status != http.StatusNotModified
+	if bodyAllowed && h.Get("Content-Length") == "" && h.Get("Transfer-Encoding") == "" {
+		h.Set("Content-Length", strconv.FormatInt(hw.written, 10))
+	}
+	hw.ResponseWriter.WriteHeader(hw.status)
 }
 
 // handleGet: GET 요청 처리
@@ -81,37 +376,46 @@ func handleGet(w http.ResponseWriter, r *http.Request) {
 	fmt.Println("## handleGet() activated.")
 
 	// 쿼리 파라미터 확인 (http://localhost:8080/?var1=9&var2=9)
+	// 응답 형식 선택용 format 파라미터는 계산 파라미터에서 제외
 	params := r.URL.Query()
+	params.Del("format")
 
 	if len(params) > 0 {
-		// 계산을 위한 GET 요청
-		var1Str := params.Get("var1")
-		var2Str := params.Get("var2")
-
-		var1, err1 := strconv.Atoi(var1Str)
-		var2, err2 := strconv.Atoi(var2Str)
-
-		if err1 != nil || err2 != nil {
-			response := fmt.Sprintf("<html>Error: Invalid parameter(s) for calculation. Expected integers.</html>")
-			io.WriteString(w, response)
-			fmt.Println("## GET request error: Invalid parameter(s).")
+		// 계산을 위한 GET 요청 (var1/var2 곱셈, expr 수식, mode=big 정밀도 모드)
+		res, err := calculate(params)
+		if r.Method != http.MethodHead { // HEAD는 기록하지 않음 (부수 효과 없는 메서드)
+			recordCalc(r, "GET", params, res, err)
+		}
+		if err != nil {
+			respondError(w, r, http.StatusBadRequest, err.Error())
+			fmt.Printf("## GET request error: %v\n", err)
 			return
 		}
 
-		result := simpleCalc(var1, var2)
-
 		// GET 응답 생성
-		response := fmt.Sprintf("<html>GET request for calculation => %d x %d = %d</html>", var1, var2, result)
-		io.WriteString(w, response)
-		fmt.Printf("## GET request for calculation => %d x %d = %d.\n", var1, var2, result)
+		res.Method = "GET"
+		respondCalc(w, r, res)
+		fmt.Printf("## GET request for calculation => %s = %v.\n", res.Desc(), res.Result)
 	} else {
 		// 디렉토리 검색을 위한 GET 요청 (파이썬 코드의 else 블록)
-		response := fmt.Sprintf("<html><p>HTTP Request GET for Path: %s</p></html>", r.URL.Path)
-		io.WriteString(w, response)
+		// 문서 루트의 실제 파일/디렉토리를 제공하며, 목록과 오류는 협상된 형식으로 출력됩니다.
+		fileServer.ServeHTTP(w, r)
 		fmt.Printf("## GET request for directory => %s.\n", r.URL.Path)
 	}
 }
 
+// handleForm: 브라우저에서 var1/var2(또는 수식)를 입력해 POST로 계산을 요청하는 폼 페이지 (/calc)
+func handleForm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		respondError(w, r, http.StatusMethodNotAllowed, "Method not supported. Use GET.")
+		return
+	}
+	if err := negotiate.WriteHTML(w, http.StatusOK, formTemplate, nil); err != nil {
+		log.Printf("Error writing form page: %v", err)
+	}
+}
+
 // handlePost: POST 요청 처리
 func handlePost(w http.ResponseWriter, r *http.Request) {
 	fmt.Println("## handlePost() activated.")
@@ -119,63 +423,706 @@ func handlePost(w http.ResponseWriter, r *http.Request) {
 	// 먼저 Body 닫기 예약
 	defer r.Body.Close()
 
-	// POST 데이터 읽기
-	body, err := io.ReadAll(r.Body)
+	// Content-Type에 따라 폼(URL 인코딩), JSON, multipart 본문을 해석 (크기 제한 적용)
+	values, err := reqbody.Values(w, r, reqbody.DefaultLimits)
 	if err != nil {
-		http.Error(w, "Error reading request body", http.StatusInternalServerError)
-		log.Printf("Error reading body: %v", err)
+		var bodyErr *reqbody.Error
+		status := http.StatusBadRequest
+		if errors.As(err, &bodyErr) {
+			status = bodyErr.Status
+		}
+		if status == http.StatusUnsupportedMediaType {
+			w.Header().Set("Accept-Post", strings.Join(reqbody.SupportedTypes, ", "))
+		}
+		respondError(w, r, status, err.Error())
+		log.Printf("Error parsing POST data: %v", err)
 		return
 	}
 
-	postDataStr := string(body)
-	fmt.Printf("## POST request data => %s.\n", postDataStr)
+	fmt.Printf("## POST request data => %s.\n", values.Encode())
 
-	// URL 쿼리 형식의 POST 데이터 파싱
-	values, err := url.ParseQuery(postDataStr)
+	res, err := calculate(values)
+	recordCalc(r, "POST", values, res, err)
 	if err != nil {
-		http.Error(w, "Error parsing POST data", http.StatusBadRequest)
-		log.Printf("Error parsing POST data: %v", err)
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		fmt.Printf("## POST request error: %v\n", err)
 		return
 	}
 
-	var1Str := values.Get("var1")
-	var2Str := values.Get("var2")
+	// POST 응답 생성
+	res.Method = "POST"
+	respondCalc(w, r, res)
+	fmt.Printf("## POST request for calculation => %s = %v.\n", res.Desc(), res.Result)
+}
+
+// =================================================================
+// 계산 기록 (/history, /stats)
+// =================================================================
+
+// calcHistory: 계산 기록 저장소 (main에서 생성)
+var calcHistory *history.Store
+
+// calcEvents: 계산 이벤트 스트림 (/events, main에서 생성)
+var calcEvents *sse.Broker
+
+// calcOps: 채널(GET/POST/BATCH/WS)·연산·모드·결과별 계산 수 메트릭 (main에서 등록)
+var calcOps *metrics.CounterVec
+
+// clientIP: 요청한 클라이언트의 IP 주소
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// recordCalc: 계산 결과 또는 오류를 기록에 추가하고 "calculation" 이벤트로 발행합니다.
+// 오류인 경우 피연산자는 요청받은 값 그대로 기록합니다.
+func recordCalc(r *http.Request, method string, values url.Values, res *calcResult, calcErr error) {
+	rec := history.Record{Client: clientIP(r), Method: method, Op: "*"}
+	if res != nil {
+		rec.Var1, rec.Var2, rec.Expr, rec.Mode, rec.Result = res.Var1, res.Var2, res.Expr, res.Mode, res.Result
+	} else {
+		rec.Expr, rec.Mode = values.Get("expr"), values.Get("mode")
+		if v := values.Get("var1"); v != "" {
+			rec.Var1 = v
+		}
+		if v := values.Get("var2"); v != "" {
+			rec.Var2 = v
+		}
+	}
+	if rec.Expr != "" || values.Has("expr") {
+		rec.Op = "expr"
+		if len(rec.Expr) > maxExprLength {
+			// 길이 제한을 넘어 거부된 수식은 앞부분만 기록
+			rec.Expr = strings.ToValidUTF8(rec.Expr[:maxExprLength], "") + "..."
+		}
+	}
+	if calcErr != nil {
+		rec.Error = calcErr.Error()
+	}
+	rec, err := calcHistory.Add(rec)
+	if err != nil {
+		log.Printf("Error saving calculation history: %v", err)
+	}
+	calcEvents.Publish("calculation", rec)
+
+	// 실패한 요청의 mode는 검증 전 입력값이므로 레이블 값을 정해진 값으로 제한
+	mode, result := "int", "ok"
+	if rec.Mode == modeBig {
+		mode = modeBig
+	}
+	if calcErr != nil {
+		result = "error"
+	}
+	calcOps.With(method, rec.Op, mode, result).Inc()
+}
 
-	var1, err1 := strconv.Atoi(var1Str)
-	var2, err2 := strconv.Atoi(var2Str)
+// historyParams: /history 조회 파라미터
+// since/until은 RFC 3339 시각(2026-01-02T15:04:05Z) 또는 현재로부터의 기간(10m, 24h)입니다.
+type historyParams struct {
+	Since  string `param:"since"`
+	Until  string `param:"until"`
+	Client string `param:"client"`
+	Offset int    `param:"offset" default:"0" validate:"min=0"`
+	Limit  int    `param:"limit" default:"50" validate:"min=1,max=1000"`
+}
 
-	if err1 != nil || err2 != nil {
-		response := fmt.Sprintf("<html>Error: Invalid parameter(s) for calculation. Expected integers.</html>")
-		io.WriteString(w, response)
-		fmt.Println("## POST request error: Invalid parameter(s).")
+// parseTimeParam: RFC 3339 시각 또는 "지금으로부터 얼마 전"의 기간을 시각으로 변환합니다.
+func parseTimeParam(name, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%s: must be an RFC 3339 time or a duration like 10m (got %q)", name, s)
+}
+
+var (
+	historyTemplate = views.Page("history")
+	statsTemplate   = views.Page("stats")
+)
+
+// statsView: 통계 응답 데이터 (HTML 템플릿용 백분율 포함)
+type statsView struct {
+	history.Stats
+}
+
+// ErrorPercent: 오류율(%)
+func (s statsView) ErrorPercent() float64 {
+	return s.ErrorRate * 100
+}
+
+// describeRecord: 기록 하나를 텍스트 한 줄로 표현합니다.
+func describeRecord(rec history.Record) string {
+	desc := rec.Expr
+	if desc == "" {
+		operand := func(v any) any {
+			if v == nil {
+				return "?" // 요청에 없던 피연산자
+			}
+			return v
+		}
+		desc = fmt.Sprintf("%v x %v", operand(rec.Var1), operand(rec.Var2))
+	}
+	result := fmt.Sprint(rec.Result)
+	if rec.Error != "" {
+		result = "Error: " + rec.Error
+	}
+	return fmt.Sprintf("#%d %s %s %s %s => %s", rec.ID, rec.Time.UTC().Format(time.RFC3339), rec.Client, rec.Method, desc, result)
+}
+
+// handleHistory: 계산 기록을 최신 순으로 조회합니다.
+// 예: /history?client=127.0.0.1&since=1h&offset=0&limit=20
+func handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		respondError(w, r, http.StatusMethodNotAllowed, "Method not supported. Use GET.")
+		return
+	}
+
+	query := r.URL.Query()
+	query.Del("format")
+	var params historyParams
+	if err := binder.Bind(query, &params); err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	since, err := parseTimeParam("since", params.Since)
+	if err == nil {
+		var until time.Time
+		if until, err = parseTimeParam("until", params.Until); err == nil {
+			page := calcHistory.Find(history.Query{
+				Since: since, Until: until, Client: params.Client,
+				Offset: params.Offset, Limit: params.Limit,
+			})
+			lines := make([]string, 0, len(page.Records)+1)
+			lines = append(lines, fmt.Sprintf("%d of %d record(s), offset %d", len(page.Records), page.Total, page.Offset))
+			for _, rec := range page.Records {
+				lines = append(lines, describeRecord(rec))
+			}
+			respond(w, r, http.StatusOK, historyTemplate, page, strings.Join(lines, "\n"))
+			return
+		}
+	}
+	respondError(w, r, http.StatusBadRequest, "Invalid parameter(s): "+err.Error())
+}
+
+// handleStats: 연산별 횟수, 오류율, 요청이 많은 클라이언트를 응답합니다.
+// 예: /stats?top=10
+func handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		respondError(w, r, http.StatusMethodNotAllowed, "Method not supported. Use GET.")
+		return
+	}
+
+	var params struct {
+		Top int `param:"top" default:"5" validate:"min=1,max=100"`
+	}
+	query := r.URL.Query()
+	query.Del("format")
+	if err := binder.Bind(query, &params); err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	result := simpleCalc(var1, var2)
+	st := statsView{calcHistory.Stats(params.Top)}
+	lines := []string{fmt.Sprintf("%d calculation(s), %d error(s) (%.1f%%)", st.Total, st.Errors, st.ErrorPercent())}
+	ops := make([]string, 0, len(st.ByOp))
+	for op := range st.ByOp {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		lines = append(lines, fmt.Sprintf("op %s: %d", op, st.ByOp[op]))
+	}
+	for _, c := range st.TopClients {
+		lines = append(lines, fmt.Sprintf("client %s: %d", c.Client, c.Count))
+	}
+	respond(w, r, http.StatusOK, statsTemplate, st, strings.Join(lines, "\n"))
+}
 
-	// POST 응답 생성
-	postResponse := fmt.Sprintf("POST request for calculation => %d x %d = %d", var1, var2, result)
-	io.WriteString(w, postResponse)
-	fmt.Printf("## POST request for calculation => %d x %d = %d.\n", var1, var2, result)
+// =================================================================
+// 일괄 계산 (/batch)
+// =================================================================
+
+// batchConfig: 일괄 계산 요청의 제한값 (main에서 플래그로 설정)
+type batchConfig struct {
+	MaxItems   int   // 요청 하나에 담을 수 있는 최대 항목 수
+	MaxBytes   int64 // 요청 본문 최대 크기
+	MaxWorkers int   // ?parallel=N으로 요청할 수 있는 최대 워커 수
+}
+
+var batchLimits = batchConfig{MaxItems: 1000, MaxBytes: 1 << 20, MaxWorkers: 8}
+
+// batchParams: /batch 요청의 쿼리 파라미터
+type batchParams struct {
+	Parallel int  `param:"parallel" default:"1" validate:"min=1"`
+	Ordered  bool `param:"ordered" default:"true"`
+}
+
+// batchItemResult: 항목 하나의 결과 (성공 시 calcResult 필드, 실패 시 error)
+// 예: {"index":0,"var1":9,"var2":9,"op":"*","result":81}, {"index":1,"error":"..."}
+type batchItemResult struct {
+	Index int `json:"index"`
+	ID    any `json:"id,omitempty"`
+	*calcResult
+	Error string `json:"error,omitempty"`
+}
+
+// calculateItem: JSON 객체 하나({"var1":9,"var2":9} 또는 {"expr":"1+2"})를 계산하고 기록합니다.
+// "id" 필드가 있으면 결과에 그대로 돌려줍니다. (/batch, /ws 공통)
+func calculateItem(r *http.Request, method string, raw json.RawMessage) batchItemResult {
+	var item batchItemResult
+	var meta struct {
+		ID any `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &meta); err == nil {
+		item.ID = meta.ID
+	}
+
+	values, err := reqbody.JSONValues(raw)
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+	values.Del("id")
+
+	res, err := calculate(values)
+	recordCalc(r, method, values, res, err)
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+	item.calcResult = res
+	return item
+}
+
+// handleBatch: 계산 요청 여러 개를 한 번에 처리합니다.
+//   - application/json: JSON 배열을 받아 결과 JSON 배열을 입력 순서대로 응답
+//   - application/x-ndjson: 한 줄에 하나씩 받은 요청을 읽는 대로 처리하여 결과를 한 줄씩 스트리밍
+//
+// ?parallel=N이면 N개의 워커로 병렬 계산하고, NDJSON에서 ?ordered=false이면 완료되는 대로 응답합니다.
+func handleBatch(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("## handleBatch() activated.")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		respondError(w, r, http.StatusMethodNotAllowed, "Method not supported. Use POST.")
+		return
+	}
+	defer r.Body.Close()
+
+	var params batchParams
+	if err := binder.Bind(r.URL.Query(), &params); err != nil {
+		respondError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	workers := min(params.Parallel, batchLimits.MaxWorkers)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	body := http.MaxBytesReader(w, r.Body, batchLimits.MaxBytes)
+
+	switch mediaType {
+	case "application/json":
+		handleBatchJSON(w, r, body, workers)
+	case "application/x-ndjson", "application/jsonl":
+		handleBatchNDJSON(w, r, body, workers, params.Ordered)
+	default:
+		w.Header().Set("Accept-Post", "application/json, application/x-ndjson")
+		respondError(w, r, http.StatusUnsupportedMediaType, fmt.Sprintf("Unsupported Content-Type %q. Use application/json (array) or application/x-ndjson.", mediaType))
+	}
+}
+
+// handleBatchJSON: JSON 배열 일괄 요청
+func handleBatchJSON(w http.ResponseWriter, r *http.Request, body io.Reader, workers int) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&items); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			respondError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("Batch body exceeds %d bytes", batchLimits.MaxBytes))
+			return
+		}
+		respondError(w, r, http.StatusBadRequest, "Batch body must be a JSON array of calculation objects: "+err.Error())
+		return
+	}
+	if len(items) > batchLimits.MaxItems {
+		respondError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("Batch has %d items, max %d", len(items), batchLimits.MaxItems))
+		return
+	}
+
+	in := make(chan batch.Item[json.RawMessage])
+	go func() {
+		defer close(in)
+		for i, raw := range items {
+			in <- batch.Item[json.RawMessage]{Index: i, Value: raw}
+		}
+	}()
+
+	results := make([]batchItemResult, len(items))
+	failed := 0
+	batch.Process(r.Context(), in, workers, true,
+		func(ctx context.Context, raw json.RawMessage) batchItemResult { return calculateItem(r, "BATCH", raw) },
+		func(i int, res batchItemResult) error {
+			res.Index = i
+			results[i] = res
+			if res.Error != "" {
+				failed++
+			}
+			return nil
+		})
+
+	negotiate.WriteJSON(w, http.StatusOK, results)
+	fmt.Printf("## Batch request => %d item(s), %d error(s).\n", len(items), failed)
+}
+
+// handleBatchNDJSON: NDJSON 스트리밍 일괄 요청 (요청을 읽는 동안 결과를 전송)
+func handleBatchNDJSON(w http.ResponseWriter, r *http.Request, body io.Reader, workers int, ordered bool) {
+	rc := http.NewResponseController(w)
+	// HTTP/1.1에서도 요청 본문을 읽으면서 응답을 쓸 수 있도록 설정
+	rc.EnableFullDuplex()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	in := make(chan batch.Item[json.RawMessage])
+	var readErr error
+	go func() {
+		defer close(in)
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 64<<10), int(batchLimits.MaxBytes))
+		index := 0
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			if index >= batchLimits.MaxItems {
+				readErr = fmt.Errorf("batch exceeds %d items, remaining lines ignored", batchLimits.MaxItems)
+				return
+			}
+			select {
+			case in <- batch.Item[json.RawMessage]{Index: index, Value: append(json.RawMessage(nil), line...)}:
+				index++
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			readErr = err
+		}
+	}()
+
+	enc := json.NewEncoder(w)
+	count, failed := 0, 0
+	err := batch.Process(r.Context(), in, workers, ordered,
+		func(ctx context.Context, raw json.RawMessage) batchItemResult { return calculateItem(r, "BATCH", raw) },
+		func(i int, res batchItemResult) error {
+			res.Index = i
+			count++
+			if res.Error != "" {
+				failed++
+			}
+			if err := enc.Encode(res); err != nil {
+				return err
+			}
+			return rc.Flush()
+		})
+	if err == nil && readErr != nil {
+		// 처리된 결과 뒤에 스트림 수준의 오류를 한 줄로 알림
+		enc.Encode(map[string]string{"error": readErr.Error()})
+	}
+	fmt.Printf("## Batch stream => %d item(s), %d error(s).\n", count, failed)
+}
+
+// =================================================================
+// WebSocket 계산 세션 (/ws)
+// =================================================================
+
+const (
+	wsPingInterval = 30 * time.Second // 서버가 ping을 보내는 간격
+	wsPongWait     = 60 * time.Second // 이 시간 동안 아무 프레임도 받지 못하면 연결 종료
+)
+
+// wsSessions: 열려 있는 WebSocket 연결 (서버 종료 시 1001 Going Away로 닫기 위해 보관)
+var wsSessions = struct {
+	sync.Mutex
+	conns map[*websocket.Conn]struct{}
+}{conns: make(map[*websocket.Conn]struct{})}
+
+// closeWebSockets: 열려 있는 모든 WebSocket 연결에 종료 프레임을 보냅니다.
+// 읽지 않는 상대에게 쓰는 동안 새 세션이 막히지 않도록 목록만 복사해 잠금을 풀고, 연결마다 동시에 보냅니다.
+// (종료 프레임 쓰기는 연결마다 CloseTimeout까지만 기다림)
+func closeWebSockets(code int, reason string) {
+	wsSessions.Lock()
+	conns := make([]*websocket.Conn, 0, len(wsSessions.conns))
+	for conn := range wsSessions.conns {
+		conns = append(conns, conn)
+	}
+	wsSessions.Unlock()
+
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn.WriteClose(code, reason)
+		}()
+	}
+	wg.Wait()
+}
+
+// handleWebSocket: WebSocket 연결로 계산 요청을 주고받습니다.
+// 텍스트 메시지 하나가 요청 하나이며, 수식("1+2*3") 또는 JSON 객체({"id":1,"var1":9,"var2":9})를 받습니다.
+// 응답은 /batch 항목과 같은 형식의 JSON이며, index는 세션 안에서의 요청 순번입니다.
+func handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	fmt.Println("## handleWebSocket() activated.")
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		status := http.StatusBadRequest
+		var hsErr *websocket.HandshakeError
+		if errors.As(err, &hsErr) {
+			status = hsErr.Status
+		}
+		if status == http.StatusMethodNotAllowed {
+			w.Header().Set("Allow", http.MethodGet)
+		}
+		respondError(w, r, status, err.Error())
+		return
+	}
+	defer conn.Close()
+
+	wsSessions.Lock()
+	wsSessions.conns[conn] = struct{}{}
+	wsSessions.Unlock()
+	defer func() {
+		wsSessions.Lock()
+		delete(wsSessions.conns, conn)
+		wsSessions.Unlock()
+	}()
+	fmt.Printf("## WebSocket session opened from %s.\n", r.RemoteAddr)
+
+	// 주기적으로 ping을 보내고, pong(또는 다른 프레임)이 오지 않으면 읽기 기한 초과로 종료
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.OnPong = func([]byte) { conn.SetReadDeadline(time.Now().Add(wsPongWait)) }
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(wsPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := conn.WritePing(nil); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for seq := 0; ; seq++ {
+		mt, msg, err := conn.ReadMessage()
+		if err != nil {
+			fmt.Printf("## WebSocket session from %s closed: %v.\n", r.RemoteAddr, err)
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		var res batchItemResult
+		msg = bytes.TrimSpace(msg)
+		switch {
+		case mt != websocket.TextMessage:
+			res.Error = "binary messages are not supported; send an expression or a JSON object as text"
+		case len(msg) > 0 && msg[0] == '{':
+			res = calculateItem(r, "WS", msg)
+		default:
+			values := url.Values{"expr": {string(msg)}}
+			calcRes, err := calculate(values)
+			recordCalc(r, "WS", values, calcRes, err)
+			if err != nil {
+				res.Error = err.Error()
+			}
+			res.calcResult = calcRes
+		}
+		res.Index = seq
+
+		reply, err := json.Marshal(res)
+		if err != nil {
+			log.Printf("Error encoding WebSocket reply: %v", err)
+			conn.WriteClose(websocket.CloseInternalError, "")
+			return
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, reply); err != nil {
+			fmt.Printf("## WebSocket session from %s closed: %v.\n", r.RemoteAddr, err)
+			return
+		}
+		fmt.Printf("## WebSocket request => %s\n", reply)
+	}
 }
 
 func main() {
-	serverName := "localhost"
-	serverPort := "8080"
-	addr := ":" + serverPort
+	docRoot := flag.String("docroot", ".", "document root served for directory retrieval GET requests")
+	accessLogPath := flag.String("access-log", "", "access log file (default: stdout)")
+	logFormat := flag.String("log-format", "combined", "access log format: common, combined, json or logfmt")
+	logLevel := flag.String("log-level", "info", "log level: info or debug (debug also dumps request details)")
+	logMaxSize := flag.Int64("log-max-size", 0, "rotate the access log file when it exceeds this many bytes (0: never)")
+	logRotateEvery := flag.Duration("log-rotate-interval", 0, "rotate the access log file at this interval, e.g. 24h (0: never)")
+	logMaxBackups := flag.Int("log-max-backups", 7, "number of rotated access log files to keep (0: keep all)")
+	historyFile := flag.String("history-file", "", "file to persist calculation history across restarts (empty: memory only)")
+	historySize := flag.Int("history-size", 1000, "number of calculation records to keep")
+	eventsHistory := flag.Int("events-history", sse.DefaultHistory, "number of recent /events kept for Last-Event-ID resume")
+	flag.IntVar(&batchLimits.MaxItems, "batch-max-items", batchLimits.MaxItems, "maximum number of items in one /batch request")
+	flag.Int64Var(&batchLimits.MaxBytes, "batch-max-bytes", batchLimits.MaxBytes, "maximum /batch request body size in bytes")
+	writeDirs := flag.String("write-dirs", "", "comma-separated docroot directories that accept PUT and DELETE, e.g. temp (empty: read-only)")
+	writeToken := flag.String("write-token", "", "require \"Authorization: Bearer <token>\" for PUT and DELETE")
+	writeClients := flag.String("write-clients", "", "comma-separated mTLS client names allowed to PUT and DELETE (requires -tls-client-ca)")
+	maxUpload := flag.Int64("max-upload-bytes", fileserver.DefaultMaxUploadBytes, "maximum PUT body size in bytes")
+	flag.IntVar(&batchLimits.MaxWorkers, "batch-max-workers", batchLimits.MaxWorkers, "maximum parallel workers a /batch request may use")
 
-	// http.HandleFunc를 사용하여 모든 경로 "/"에 대해 myHttpHandler 함수를 등록합니다.
-	http.HandleFunc("/", myHttpHandler)
+	// 타임아웃, 헤더 크기 제한, 종료 대기 시간 (-read-timeout 등의 플래그로 변경 가능)
+	cfg := server.DefaultConfig(":8080")
+	cfg.RegisterFlags(flag.CommandLine)
+	// 설정 순서: 기본값 → -config JSON 파일 → CALC_* 환경 변수 (예: CALC_RATE_LIMIT=5) → 명령행 플래그
+	loader := config.New("CALC", flag.CommandLine)
+	if err := loader.Load(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
 
-	fmt.Printf("## HTTP server started at http://%s:%s.\n", serverName, serverPort)
+	// 접근 로그 설정
+	format, err := accesslog.ParseFormat(*logFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+	level, err := accesslog.ParseLevel(*logLevel)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var logOut io.Writer = os.Stdout
+	var logFile *accesslog.RotatingFile
+	if *accessLogPath != "" {
+		logFile, err = accesslog.OpenRotatingFile(*accessLogPath, *logMaxSize, *logRotateEvery, *logMaxBackups)
+		if err != nil {
+			log.Fatalf("Error opening access log: %v", err)
+		}
+		defer logFile.Close()
+		logOut = logFile
+	}
+	accessLogger := accesslog.New(logOut, format, level)
 
-	// http.ListenAndServe를 사용하여 서버를 시작합니다.
-	// 이 함수는 오류가 발생하거나 프로그램이 종료될 때까지 블록됩니다.
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		if err == http.ErrServerClosed {
-			fmt.Println("HTTP server stopped.")
-		} else {
-			log.Fatalf("Error starting server: %v", err)
+	// 정적 파일 서버 생성 (문서 루트 밖으로의 접근은 차단됨)
+	fs, err := fileserver.New(*docRoot, renderListing)
+	if err != nil {
+		log.Fatalf("Error opening document root: %v", err)
+	}
+	defer fs.Close()
+	fs.RenderError = respondError
+	fs.MaxUploadBytes = *maxUpload
+	// PUT/DELETE 권한 검사 훅: 허용 디렉토리, Bearer 토큰, mTLS 클라이언트 이름 (모두 통과해야 허용)
+	if *writeDirs != "" {
+		fs.WriteAuthorizers = append(fs.WriteAuthorizers, fileserver.UnderDirs(strings.Split(*writeDirs, ",")...))
+		if *writeToken != "" {
+			fs.WriteAuthorizers = append(fs.WriteAuthorizers, fileserver.BearerToken(*writeToken))
 		}
+		if *writeClients != "" {
+			fs.WriteAuthorizers = append(fs.WriteAuthorizers, fileserver.ClientNames(strings.Split(*writeClients, ",")...))
+		}
+		if *writeToken == "" && *writeClients == "" {
+			log.Printf("## WARNING: PUT/DELETE under %s are allowed without authentication (set -write-token or -write-clients).", *writeDirs)
+		}
+	} else if *writeToken != "" || *writeClients != "" {
+		log.Fatal("-write-token and -write-clients require -write-dirs")
+	}
+	fileServer = fs
+
+	// 계산 기록 저장소 (-history-file이 있으면 이전 기록을 불러옴)
+	calcHistory, err = history.Open(*historyFile, *historySize)
+	if err != nil {
+		log.Fatalf("Error opening calculation history: %v", err)
+	}
+	calcEvents = sse.NewBroker(*eventsHistory)
+
+	// 모든 경로 "/"에 대해 myHttpHandler 함수를 등록합니다.
+	// 전용 ServeMux 사용: expvar, net/http/pprof가 http.DefaultServeMux에 등록하는 /debug/ 경로가 공개 포트에 노출되지 않게 함
+	mux := http.NewServeMux()
+	// 접근 로그 미들웨어가 상태 코드, 전송 바이트 수, 처리 시간 등을 기록합니다.
+	mux.Handle("/", accessLogger.Middleware(http.HandlerFunc(myHttpHandler)))
+	// 브라우저용 계산 폼 (POST /로 제출)
+	mux.Handle("/calc", accessLogger.Middleware(http.HandlerFunc(handleForm)))
+	// 일괄 계산: JSON 배열 또는 NDJSON 스트림
+	mux.Handle("/batch", accessLogger.Middleware(http.HandlerFunc(handleBatch)))
+	// 계산 기록 조회와 통계
+	mux.Handle("/history", accessLogger.Middleware(http.HandlerFunc(handleHistory)))
+	mux.Handle("/stats", accessLogger.Middleware(http.HandlerFunc(handleStats)))
+	// WebSocket 계산 세션
+	mux.Handle("/ws", accessLogger.Middleware(http.HandlerFunc(handleWebSocket)))
+	// 계산 이벤트 스트림 (Server-Sent Events)
+	mux.Handle("/events", accessLogger.Middleware(calcEvents))
+
+	srv := server.New(cfg, mux)
+	// 오케스트레이터용 상태 확인 (종료가 시작되면 /readyz는 503). 진단용 /debug/는 -admin-addr에서만 제공
+	mux.Handle("/healthz", srv.Health.LivenessHandler())
+	mux.Handle("/readyz", srv.Health.ReadinessHandler())
+	// 기록 파일에 쓸 수 없으면(디스크 가득 참 등) 준비되지 않은 것으로 봄
+	srv.Health.Add("history", func(context.Context) error { return calcHistory.Err() })
+	// Prometheus 메트릭: HTTP 요청 메트릭(서버가 기록)과 계산 메트릭
+	mux.Handle("/metrics", srv.Metrics.Handler())
+	calcOps = srv.Metrics.NewCounterVec("calc_operations_total",
+		"Calculations by channel, operation (* or expr), mode and result.", "channel", "op", "mode", "result")
+	srv.Metrics.NewGaugeFunc("calc_event_subscribers", "Clients connected to the /events stream.",
+		func() float64 { return float64(calcEvents.Clients()) })
+	// 이벤트 스트림은 끝나지 않는 요청이므로, 종료가 시작되면 바로 끊어 처리 중인 요청 대기를 막지 않게 함
+	srv.OnShutdownStart(calcEvents.Close)
+	// 속도 제한(429)과 과부하(503) 응답도 다른 오류와 같은 형식(JSON 또는 텍스트)으로 보냄
+	srv.ErrorHandler = respondError
+
+	// 종료 신호를 받으면 처리 중인 요청을 마친 뒤 계산 기록과 접근 로그를 디스크에 기록합니다.
+	srv.OnShutdown(func(ctx context.Context) error {
+		// Hijack된 WebSocket 연결은 http.Server가 관리하지 않으므로 직접 종료를 알림
+		closeWebSockets(websocket.CloseGoingAway, "server shutting down")
+		return calcHistory.Close()
+	})
+	srv.OnShutdown(func(ctx context.Context) error {
+		if logFile == nil {
+			return nil
+		}
+		fmt.Println("## Flushing access log.")
+		return logFile.Sync()
+	})
+
+	// SIGHUP: 설정 파일과 환경 변수를 다시 읽어 로그 수준, 속도 제한, CORS 정책, 압축 설정을 재시작 없이 적용
+	loader.Watch(append([]string{"log-level"}, server.ReloadableFlags...), func(changed []string) error {
+		level, err := accesslog.ParseLevel(*logLevel)
+		if err != nil {
+			return err
+		}
+		if err := srv.Reload(cfg); err != nil {
+			return err
+		}
+		accessLogger.SetLevel(level)
+		return nil
+	})
+
+	fmt.Printf("## HTTP server started at %s.\n", srv.URL())
+	fmt.Printf("## Serving files from %s.\n", *docRoot)
+	if cfg.Engine == server.EngineRaw {
+		fmt.Println("## Using the from-scratch HTTP/1.1 engine (internal/rawhttp).")
+	}
+
+	// srv.Run은 SIGINT/SIGTERM을 받을 때까지 블록되며, 신호를 받으면 우아하게 종료합니다.
+	if err := srv.Run(); err != nil {
+		log.Fatalf("Error running server: %v", err)
 	}
+	fmt.Println("HTTP server stopped.")
 }
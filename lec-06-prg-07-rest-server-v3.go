@@ -1,13 +1,25 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
+	"os"
 	"strings"
 	"sync" // 동시성 제어를 위한 패키지
+
+	"full_stack_service_networking_project/internal/accesslog"
+	"full_stack_service_networking_project/internal/binder"
+	"full_stack_service_networking_project/internal/config"
+	"full_stack_service_networking_project/internal/identity"
+	"full_stack_service_networking_project/internal/metrics"
+	"full_stack_service_networking_project/internal/reqbody"
+	"full_stack_service_networking_project/internal/server"
+	"full_stack_service_networking_project/internal/sse"
 )
 
 // MembershipHandler: Python의 MembershipHandler 클래스에 해당하는 Go Struct
@@ -16,6 +28,11 @@ type MembershipHandler struct {
 	// Mutex: Map 접근 시 동시성 문제를 해결하기 위한 읽기/쓰기 락
 	database map[string]string
 	mu       sync.RWMutex
+	// events: 회원 생성/수정/삭제 이벤트 스트림 (/events)
+	// Publish는 블록되지 않으므로 락을 쥔 채로 발행해도 느린 구독자가 핸들러를 막지 않습니다.
+	events *sse.Broker
+	// changes: 이벤트 종류별 회원 변경 수 메트릭 (main에서 등록, nil이면 기록하지 않음)
+	changes *metrics.CounterVec
 }
 
 // 응답 구조체
@@ -25,12 +42,28 @@ type Response struct {
 }
 
 // 새 핸들러 인스턴스를 생성하는 생성자
-func NewMembershipHandler() *MembershipHandler {
+func NewMembershipHandler(eventsHistory int) *MembershipHandler {
 	return &MembershipHandler{
 		database: make(map[string]string),
+		events:   sse.NewBroker(eventsHistory),
+	}
+}
+
+// publish: 변경 이벤트를 발행하고 변경 수 메트릭을 올립니다. (쓰기 락을 쥔 상태에서 호출)
+func (m *MembershipHandler) publish(event string, data Response) {
+	m.events.Publish(event, data)
+	if m.changes != nil {
+		m.changes.With(event).Inc()
 	}
 }
 
+// size: 현재 회원 수
+func (m *MembershipHandler) size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.database)
+}
+
 // =================================================================
 // Go 언어의 RESTful 핸들러 함수들
 // =================================================================
@@ -51,24 +84,46 @@ func handleSuccessResponse(w http.ResponseWriter, id string, value string, statu
 	json.NewEncoder(w).Encode(response)
 }
 
+// memberForm: 회원 생성/수정 요청 파라미터
+type memberForm struct {
+	Value string `param:"value" validate:"required,max=1024"`
+}
+
+// bindMemberForm: 요청 본문(폼/JSON/multipart)과 쿼리 파라미터를 memberForm으로 변환
+// Python 코드처럼 'value' 대신 회원 ID를 키로 보내는 요청({"0001": "apple"})도 허용합니다.
+func bindMemberForm(w http.ResponseWriter, r *http.Request, memberID string) (*memberForm, int, error) {
+	values, err := reqbody.Values(w, r, reqbody.DefaultLimits)
+	if err != nil {
+		var bodyErr *reqbody.Error
+		if errors.As(err, &bodyErr) {
+			return nil, bodyErr.Status, err
+		}
+		return nil, http.StatusBadRequest, err
+	}
+	// 본문에 없는 키는 쿼리 파라미터에서 찾음 (r.FormValue와 같은 동작)
+	for key, vs := range r.URL.Query() {
+		if !values.Has(key) {
+			values[key] = vs
+		}
+	}
+
+	var form memberForm
+	if err := binder.Bind(values, &form, binder.WithAlias("value", memberID)); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return &form, http.StatusOK, nil
+}
+
 // create (POST): 새 멤버를 추가
 func (m *MembershipHandler) create(w http.ResponseWriter, r *http.Request, memberID string) {
 	// Python 코드에서는 request.form[member_id]를 사용했습니다.
-	// Go에서는 POST Body에서 해당 값을 파싱해야 합니다.
-	
-	// 폼 데이터 파싱
-	r.ParseForm()
-	value := r.FormValue("value") // 요청 본문에서 'value' 필드를 추출한다고 가정
-	
-	if value == "" {
-		// Python 코드에서는 폼 키가 member_id와 같았으나, 일반적인 REST 방식에 맞춰 'value'로 가정
-		value = r.FormValue(memberID)
-	}
-
-	if value == "" {
-		handleErrorResponse(w, memberID, "Value field missing in POST data", http.StatusBadRequest)
+	// 'value' 필드 또는 member_id 키의 값을 사용합니다.
+	form, status, err := bindMemberForm(w, r, memberID)
+	if err != nil {
+		handleErrorResponse(w, memberID, err.Error(), status)
 		return
 	}
+	value := form.Value
 
 	// 락 획득 (쓰기)
 	m.mu.Lock()
@@ -80,6 +135,7 @@ func (m *MembershipHandler) create(w http.ResponseWriter, r *http.Request, membe
 	}
 
 	m.database[memberID] = value
+	m.publish("member.created", Response{ID: memberID, Value: value})
 	handleSuccessResponse(w, memberID, value, http.StatusCreated) // 201 Created
 }
 
@@ -100,22 +156,14 @@ func (m *MembershipHandler) read(w http.ResponseWriter, r *http.Request, memberI
 
 // update (PUT): 멤버 정보를 수정
 func (m *MembershipHandler) update(w http.ResponseWriter, r *http.Request, memberID string) {
-	// PUT 요청에서 본문 데이터 읽기 (Go의 PUT은 폼 데이터를 자동으로 파싱하지 않음)
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		handleErrorResponse(w, memberID, "Error reading request body", http.StatusInternalServerError)
-		return
-	}
-	
-	// 본문 데이터를 폼 형식(URL 쿼리 형식)으로 파싱
-	values := parseFormData(string(body))
+	// PUT 요청 본문 해석 (Go의 PUT은 폼 데이터를 자동으로 파싱하지 않음)
+	form, status, err := bindMemberForm(w, r, memberID)
 	if err != nil {
-		handleErrorResponse(w, memberID, "Invalid PUT body format", http.StatusBadRequest)
+		handleErrorResponse(w, memberID, err.Error(), status)
 		return
 	}
-	
-	value := values["value"] // 요청 본문에서 'value' 필드를 추출한다고 가정
-	
+	value := form.Value
+
 	// 락 획득 (쓰기)
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -126,6 +174,7 @@ func (m *MembershipHandler) update(w http.ResponseWriter, r *http.Request, membe
 	}
 
 	m.database[memberID] = value
+	m.publish("member.updated", Response{ID: memberID, Value: value})
 	handleSuccessResponse(w, memberID, value, http.StatusOK)
 }
 
@@ -141,23 +190,10 @@ func (m *MembershipHandler) delete(w http.ResponseWriter, r *http.Request, membe
 	}
 
 	delete(m.database, memberID)
+	m.publish("member.deleted", Response{ID: memberID})
 	handleSuccessResponse(w, memberID, "Removed", http.StatusOK)
 }
 
-// parseFormData: PUT 요청의 본문 데이터를 폼 형식으로 파싱하는 헬퍼 함수
-func parseFormData(data string) map[string]string {
-	result := make(map[string]string)
-	pairs := strings.Split(data, "&")
-	for _, pair := range pairs {
-		parts := strings.SplitN(pair, "=", 2)
-		if len(parts) == 2 {
-			// URL 디코딩을 수행해야 하지만, 여기서는 단순 문자열로 가정
-			result[parts[0]] = parts[1]
-		}
-	}
-	return result
-}
-
 // =================================================================
 // 라우팅 및 메인 함수
 // =================================================================
@@ -172,7 +208,10 @@ func (m *MembershipHandler) mainHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	memberID := parts[len(parts)-1]
-	
+
+	// mTLS 모드(-tls-client-ca)에서는 클라이언트 인증서의 신원이 컨텍스트로 전달됩니다.
+	fmt.Printf("## %s member %s requested by %s\n", r.Method, memberID, identity.Name(r.Context()))
+
 	// HTTP 메서드에 따라 적절한 CRUD 함수 호출
 	switch r.Method {
 	case "POST":
@@ -189,17 +228,83 @@ func (m *MembershipHandler) mainHandler(w http.ResponseWriter, r *http.Request)
 }
 
 func main() {
+	// 타임아웃, 헤더 크기 제한, 종료 대기 시간 (-read-timeout 등의 플래그로 변경 가능)
+	cfg := server.DefaultConfig(":5000") // Flask 기본 포트 5000을 사용
+	cfg.RegisterFlags(flag.CommandLine)
+	logFormat := flag.String("log-format", "combined", "access log format: common, combined, json or logfmt")
+	logLevel := flag.String("log-level", "info", "log level: info or debug (debug also dumps request details)")
+	eventsHistory := flag.Int("events-history", sse.DefaultHistory, "number of recent /events kept for Last-Event-ID resume")
+	// 설정 순서: 기본값 → -config JSON 파일 → MEMBERSHIP_* 환경 변수 (예: MEMBERSHIP_ADDR=:5001) → 명령행 플래그
+	loader := config.New("MEMBERSHIP", flag.CommandLine)
+	if err := loader.Load(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
 	// 핸들러 인스턴스 생성
-	myManager := NewMembershipHandler()
+	myManager := NewMembershipHandler(*eventsHistory)
+
+	// 접근 로그 (mTLS 모드에서는 호출자 신원이 사용자 필드에 기록됩니다)
+	format, err := accesslog.ParseFormat(*logFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+	level, err := accesslog.ParseLevel(*logLevel)
+	if err != nil {
+		log.Fatal(err)
+	}
+	accessLogger := accesslog.New(os.Stdout, format, level)
 
+	// 전용 ServeMux 사용: expvar, net/http/pprof가 http.DefaultServeMux에 등록하는 /debug/ 경로가 공개 포트에 노출되지 않게 함
+	mux := http.NewServeMux()
 	// 라우팅 설정: 모든 /membership_api/* 경로 요청을 myManager.mainHandler가 처리하도록 합니다.
-	http.HandleFunc("/membership_api/", myManager.mainHandler)
+	mux.Handle("/membership_api/", accessLogger.Middleware(http.HandlerFunc(myManager.mainHandler)))
+	// 회원 변경 이벤트 스트림 (Server-Sent Events)
+	mux.Handle("/events", accessLogger.Middleware(myManager.events))
 
-	addr := ":5000" // Flask 기본 포트 5000을 사용
-	fmt.Printf("## RESTful API Server started at http://localhost%s\n", addr)
-	
-	// 서버 시작
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatalf("Error starting server: %v", err)
+	srv := server.New(cfg, mux)
+	// 오케스트레이터용 상태 확인 (종료가 시작되면 /readyz는 503). 진단용 /debug/는 -admin-addr에서만 제공
+	mux.Handle("/healthz", srv.Health.LivenessHandler())
+	mux.Handle("/readyz", srv.Health.ReadinessHandler())
+	// Prometheus 메트릭: HTTP 요청 메트릭(서버가 기록)과 회원 메트릭
+	mux.Handle("/metrics", srv.Metrics.Handler())
+	srv.Metrics.NewGaugeFunc("membership_members", "Members currently stored.",
+		func() float64 { return float64(myManager.size()) })
+	myManager.changes = srv.Metrics.NewCounterVec("membership_changes_total",
+		"Member changes by event (member.created, member.updated, member.deleted).", "event")
+	// 이벤트 스트림은 끝나지 않는 요청이므로, 종료가 시작되면 바로 끊어 처리 중인 요청 대기를 막지 않게 함
+	srv.OnShutdownStart(myManager.events.Close)
+	// 속도 제한(429)과 과부하(503) 응답도 다른 API 오류와 같은 JSON 형식으로 보냄
+	srv.ErrorHandler = func(w http.ResponseWriter, r *http.Request, status int, msg string) {
+		handleErrorResponse(w, "", msg, status)
 	}
-}
\ No newline at end of file
+	srv.OnShutdown(func(ctx context.Context) error {
+		myManager.mu.RLock()
+		defer myManager.mu.RUnlock()
+		fmt.Printf("## %d member(s) in memory at shutdown.\n", len(myManager.database))
+		return nil
+	})
+
+	// SIGHUP: 설정 파일과 환경 변수를 다시 읽어 로그 수준, 속도 제한, CORS 정책, 압축 설정을 재시작 없이 적용
+	loader.Watch(append([]string{"log-level"}, server.ReloadableFlags...), func(changed []string) error {
+		level, err := accesslog.ParseLevel(*logLevel)
+		if err != nil {
+			return err
+		}
+		if err := srv.Reload(cfg); err != nil {
+			return err
+		}
+		accessLogger.SetLevel(level)
+		return nil
+	})
+
+	fmt.Printf("## RESTful API Server started at %s\n", srv.URL())
+
+	// 서버 시작 (SIGINT/SIGTERM을 받으면 처리 중인 요청을 마친 뒤 종료)
+	if err := srv.Run(); err != nil {
+		log.Fatalf("Error running server: %v", err)
+	}
+	fmt.Println("## RESTful API Server stopped.")
+}
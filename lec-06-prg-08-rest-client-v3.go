@@ -2,14 +2,22 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
+
+	"full_stack_service_networking_project/internal/config"
+	"full_stack_service_networking_project/internal/httpclient"
 )
 
+// httpClient: 모든 요청에 사용하는 HTTP 클라이언트 (main에서 -cacert 설정을 반영하여 생성)
+var httpClient = &http.Client{}
+
 // ResponseBody struct는 서버로부터의 JSON 응답 구조를 나타냅니다.
 // 서버의 응답 형식이 { "id": "...", "value": "..." }로 가정합니다.
 // Python 코드에서는 { "0001": "..." } 형식이므로, 이를 처리하기 위해 Map을 사용합니다.
@@ -25,9 +33,6 @@ func performRequest(step int, method, urlStr string, data url.Values) {
 		body = strings.NewReader(data.Encode())
 	}
 
-	// HTTP 클라이언트 생성
-	client := &http.Client{}
-
 	// 요청 객체 생성
 	req, err := http.NewRequest(method, urlStr, body)
 	if err != nil {
@@ -40,7 +45,7 @@ func performRequest(step int, method, urlStr string, data url.Values) {
 	}
 
 	// 요청 수행
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		// 서버 미실행 시 발생 가능
 		fmt.Printf("#%d Error sending %s request: %v\n", step, method, err)
@@ -78,21 +83,43 @@ func performRequest(step int, method, urlStr string, data url.Values) {
 	}
 
 	jsonResult := jsonResponse[key]
-	
+
 	// Python 출력 형식에 맞춤
 	// print("#1 Code:", r.status_code, ">>", "JSON:", r.json(), ">>", "JSON Result:", r.json()['0001'])
-	fmt.Printf("#%d Code: %d >> JSON: %v >> JSON Result: %s\n", 
-		step, 
-		resp.StatusCode, 
+	fmt.Printf("#%d Code: %d >> JSON: %v >> JSON Result: %s\n",
+		step,
+		resp.StatusCode,
 		jsonResponse,
 		jsonResult,
 	)
 }
 
 func main() {
+	// 서버 주소와 신뢰할 CA (HTTPS 개발 모드에서는 서버가 생성한 certs/ca.pem 지정)
+	baseFlag := flag.String("base-url", "http://127.0.0.1:5000/membership_api/", "membership API base URL (use https:// for TLS, or unix:///path/to.sock:/membership_api/ for a server listening on a Unix socket)")
+	caFile := flag.String("cacert", "", "PEM CA bundle to trust in addition to the system roots")
+	// mTLS 서버(-tls-client-ca)에 제시할 클라이언트 인증서 (lec-06-prg-09로 발급)
+	certFile := flag.String("cert", "", "client certificate (PEM) for mutual TLS")
+	keyFile := flag.String("key", "", "client private key (PEM) for mutual TLS")
+	// 설정 순서: 기본값 → -config JSON 파일 → MEMBERSHIP_CLIENT_* 환경 변수 → 명령행 플래그
+	loader := config.New("MEMBERSHIP_CLIENT", flag.CommandLine)
+	if err := loader.Load(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+	target, err := httpclient.ParseTarget("base-url", *baseFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := httpclient.New(httpclient.Options{CAFile: *caFile, CertFile: *certFile, KeyFile: *keyFile, UnixSocket: target.UnixSocket})
+	if err != nil {
+		log.Fatalf("Error configuring HTTP client: %v", err)
+	}
+	httpClient = client
+
 	fmt.Println("## Go REST client started.")
 
-	baseURL := "http://127.0.0.1:5000/membership_api/"
+	baseURL := target.BaseURL
 
 	// --- #1 Reads a non registered member : error-case ---
 	// r = requests.get('http://127.0.0.1:5000/membership_api/0001')
@@ -122,12 +149,11 @@ func main() {
 	// 1. 등록 (POST)
 	formData6_1 := url.Values{"0002": {"xrange"}}
 	performRequest(6, "POST", baseURL+"0002", formData6_1)
-	
+
 	// 2. 수정 (PUT)
 	formData6_2 := url.Values{"0002": {"orange"}}
 	performRequest(6, "PUT", baseURL+"0002", formData6_2)
 
-
 	// --- #7 Delete a registered member : non-error case ---
 	// r = requests.delete('http://127.0.0.1:5000/membership_api/0001')
 	performRequest(7, "DELETE", baseURL+"0001", nil)
@@ -137,4 +163,4 @@ func main() {
 	performRequest(8, "DELETE", baseURL+"0001", nil)
 
 	fmt.Println("\n## Go REST client completed.")
-}
\ No newline at end of file
+}
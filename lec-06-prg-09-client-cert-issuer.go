@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"full_stack_service_networking_project/internal/config"
+	"full_stack_service_networking_project/internal/tlsutil"
+)
+
+// 클라이언트 인증서 발급 도구
+// REST 서버를 mTLS 모드(-tls-client-ca certs/ca.pem)로 실행했을 때
+// lec-06-prg-08 REST 클라이언트가 제시할 인증서를 발급합니다.
+//
+// 사용 예:
+//
+//	go run lec-06-prg-09-client-cert-issuer.go -cn alice -email alice@example.com
+//	go run lec-06-prg-08-rest-client-v3.go -base-url https://127.0.0.1:5000/membership_api/ \
+//	    -cacert certs/ca.pem -cert certs/alice.pem -key certs/alice-key.pem
+func main() {
+	certDir := flag.String("dir", "certs", "directory containing ca.pem/ca-key.pem and receiving the issued files")
+	caCert := flag.String("ca-cert", "", "CA certificate (default: <dir>/ca.pem)")
+	caKey := flag.String("ca-key", "", "CA private key (default: <dir>/ca-key.pem)")
+	initCA := flag.Bool("init-ca", false, "create a new dev CA in <dir> if none exists")
+	commonName := flag.String("cn", "", "client common name (required)")
+	emails := flag.String("email", "", "comma-separated e-mail SANs (the first one becomes the caller identity)")
+	days := flag.Int("days", 365, "validity in days")
+	out := flag.String("out", "", "output file prefix (default: <dir>/<cn>)")
+	force := flag.Bool("force", false, "overwrite an existing certificate and key with the same name")
+	// 설정 순서: 기본값 → -config JSON 파일 → CERT_ISSUER_* 환경 변수 (예: CERT_ISSUER_DIR) → 명령행 플래그
+	loader := config.New("CERT_ISSUER", flag.CommandLine)
+	if err := loader.Load(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+
+	if *commonName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *days <= 0 {
+		log.Fatalf("-days must be positive, got %d", *days)
+	}
+
+	// CA 파일 경로 결정 (기본값은 -tls-dev 모드의 서버가 생성한 certs/ca.pem)
+	devFiles := tlsutil.DevPaths(*certDir)
+	if *caCert == "" {
+		*caCert = devFiles.CACert
+	}
+	if *caKey == "" {
+		*caKey = devFiles.CAKey
+	}
+
+	ca, err := tlsutil.LoadCA(*caCert, *caKey)
+	if err != nil && *initCA && errors.Is(err, fs.ErrNotExist) {
+		fmt.Printf("## Creating a new dev CA in %s.\n", *certDir)
+		if err := os.MkdirAll(*certDir, 0o700); err != nil {
+			log.Fatalf("Error creating %s: %v", *certDir, err)
+		}
+		var keyPEM []byte
+		ca, keyPEM, err = tlsutil.NewCA("Dev Root CA", 10*365*24*time.Hour)
+		if err == nil {
+			err = tlsutil.CreateFiles(*caCert, ca.CertPEM, *caKey, keyPEM) // 한쪽만 남은 CA 파일은 덮어쓰지 않음
+		}
+	}
+	if err != nil {
+		log.Fatalf("Error loading CA (run a server with -tls-dev first, or pass -init-ca): %v", err)
+	}
+
+	// 출력 경로는 인증서를 발급하기 전에 확인 (CA와 서버 인증서를 클라이언트 인증서로 덮어쓰지 않도록)
+	prefix := *out
+	if prefix == "" {
+		if prefix, err = outputPrefix(*certDir, *commonName); err != nil {
+			log.Fatal(err)
+		}
+	}
+	certFile, keyFile := prefix+".pem", prefix+"-key.pem"
+	if err := checkOutputs([]string{certFile, keyFile}, *caCert, *caKey, devFiles.CACert, devFiles.CAKey, devFiles.ServerCert, devFiles.ServerKey); err != nil {
+		log.Fatal(err)
+	}
+
+	var emailList []string
+	for _, e := range strings.Split(*emails, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			emailList = append(emailList, e)
+		}
+	}
+
+	certPEM, keyPEM, err := ca.Issue(tlsutil.IssueOptions{
+		CommonName: *commonName,
+		Emails:     emailList,
+		Usage:      tlsutil.ClientAuth,
+		Validity:   time.Duration(*days) * 24 * time.Hour,
+	})
+	if err != nil {
+		log.Fatalf("Error issuing certificate: %v", err)
+	}
+
+	write := tlsutil.CreateFiles
+	if *force {
+		write = tlsutil.WriteFiles
+	}
+	if err := write(certFile, certPEM, keyFile, keyPEM); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			log.Fatalf("Error writing certificate: %v (pass -force to replace it)", err)
+		}
+		log.Fatalf("Error writing certificate: %v", err)
+	}
+
+	fmt.Printf("## Issued client certificate for %q signed by %q.\n", *commonName, ca.Cert.Subject.CommonName)
+	fmt.Printf("## Certificate: %s\n", certFile)
+	fmt.Printf("## Private key: %s\n", keyFile)
+}
+
+// outputPrefix: -out이 없을 때의 출력 경로 <dir>/<cn>. cn이 파일 이름으로 쓰이므로 경로 구분자와 ".."는 거부합니다.
+func outputPrefix(dir, cn string) (string, error) {
+	if strings.ContainsAny(cn, `/\`) || strings.Contains(cn, "..") || cn == "." {
+		return "", fmt.Errorf("-cn %q cannot be used as a file name (path separator or ..); pass -out", cn)
+	}
+	return filepath.Join(dir, cn), nil
+}
+
+// checkOutputs: 발급할 파일이 CA 또는 서버의 인증서/개인키 경로와 같으면 거부합니다. (-force로도 덮어쓰지 않음)
+func checkOutputs(files []string, protected ...string) error {
+	for _, f := range files {
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return err
+		}
+		for _, p := range protected {
+			if pa, err := filepath.Abs(p); err == nil && pa == abs {
+				return fmt.Errorf("%s is a CA or server file and must not be overwritten; choose another -cn or -out", f)
+			}
+		}
+	}
+	return nil
+}